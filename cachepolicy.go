@@ -0,0 +1,155 @@
+package gorest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCachePolicy is the policy of the successful GET and HEAD responses
+// of the routes without a policy, it forces the revalidation on each request.
+var defaultCachePolicy = CachePolicy{Private: true, MustRevalidate: true}
+
+// CachePolicy defines the caching of the successful GET and HEAD responses,
+// it is converted into the Cache-Control header and optionally the Expires
+// and Vary ones.
+type CachePolicy struct {
+	Public               bool          // Shared caches, e.g. CDNs, can store the response.
+	Private              bool          // Only the client cache can store the response.
+	NoStore              bool          // The response must not be stored, the other directives are ignored.
+	NoCache              bool          // The response must be revalidated before each use.
+	MustRevalidate       bool          // Stale responses must be revalidated before use.
+	Immutable            bool          // The response will not change while fresh.
+	MaxAge               time.Duration // Freshness lifetime, always sent.
+	SMaxAge              time.Duration // Freshness lifetime in shared caches, if positive.
+	StaleWhileRevalidate time.Duration // Time a stale response can be used while revalidating, if positive.
+	StaleIfError         time.Duration // Time a stale response can be used on errors, if positive.
+	Expires              bool          // Whether the Expires header is set from MaxAge, for HTTP/1.0 caches.
+	Vary                 []string      // Request headers the response varies on.
+}
+
+// CachePolicyResponse is the interface implemented by the responses defining
+// their own CachePolicy, overriding the one of the route.
+type CachePolicyResponse interface {
+	GetCachePolicy() *CachePolicy
+}
+
+// responseCachePolicy implements the caching policy methods shared by the
+// responses, it is embedded in each of them.
+type responseCachePolicy struct {
+	policy *CachePolicy
+}
+
+// SetCachePolicy can be used to set the caching policy of the response,
+// overriding the one of the route.
+func (r *responseCachePolicy) SetCachePolicy(policy CachePolicy) {
+	r.policy = &policy
+}
+
+// GetCachePolicy will be used by gorest core to retrieve the caching policy
+// of the response.
+func (r *responseCachePolicy) GetCachePolicy() *CachePolicy {
+	return r.policy
+}
+
+// String returns the Cache-Control header value of the policy.
+func (p CachePolicy) String() string {
+	if p.NoStore {
+		return "no-store"
+	}
+
+	var directives []string
+	if p.Public {
+		directives = append(directives, "public")
+	}
+	if p.Private {
+		directives = append(directives, "private")
+	}
+	if p.NoCache {
+		directives = append(directives, "no-cache")
+	}
+	directives = append(directives, "max-age="+seconds(p.MaxAge))
+	if p.SMaxAge > 0 {
+		directives = append(directives, "s-maxage="+seconds(p.SMaxAge))
+	}
+	if p.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+	if p.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate="+seconds(p.StaleWhileRevalidate))
+	}
+	if p.StaleIfError > 0 {
+		directives = append(directives, "stale-if-error="+seconds(p.StaleIfError))
+	}
+	if p.Immutable {
+		directives = append(directives, "immutable")
+	}
+	return strings.Join(directives, ", ")
+}
+
+// seconds formats the duration in whole seconds.
+func seconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
+
+// SetCachePolicy sets the caching policy of the successful GET and HEAD
+// responses of the route, which otherwise must be revalidated on each
+// request.
+// The route itself is returned to allow chaining calls.
+func (r *Route) SetCachePolicy(policy CachePolicy) *Route {
+	r.cachePolicy = &policy
+	r.resetRouters()
+	return r
+}
+
+// GetCachePolicy returns the caching policy of the route, nil if not set.
+func (r *Route) GetCachePolicy() *CachePolicy {
+	return r.cachePolicy
+}
+
+// setCachePolicy sets the caching headers of the successful GET and HEAD
+// responses, the policy of the response takes precedence over the route one
+// while a Cache-Control header set by the response is kept as is.
+func setCachePolicy(header http.Header, r *http.Request, code int, route *Route, response Response) {
+	if !isGetOrHead(r.Method) || (code != http.StatusOK && code != http.StatusNotModified) {
+		return
+	}
+	if header.Get("Cache-Control") != "" {
+		return
+	}
+
+	policy := &defaultCachePolicy
+	if route.GetCachePolicy() != nil {
+		policy = route.GetCachePolicy()
+	}
+	if res, ok := response.(CachePolicyResponse); ok && res.GetCachePolicy() != nil {
+		policy = res.GetCachePolicy()
+	}
+
+	header.Set("Cache-Control", policy.String())
+	if policy.Expires {
+		if policy.NoStore || policy.NoCache || policy.MaxAge <= 0 {
+			header.Set("Expires", "0")
+		} else {
+			header.Set("Expires", time.Now().Add(policy.MaxAge).UTC().Format(http.TimeFormat))
+		}
+	}
+	addVary(header, policy.Vary...)
+}
+
+// addVary adds the request headers, or comma separated lists of them, to the
+// Vary header skipping the ones already listed.
+func addVary(header http.Header, keys ...string) {
+	for _, value := range keys {
+		for _, key := range strings.Split(value, ",") {
+			key = http.CanonicalHeaderKey(strings.TrimSpace(key))
+			if key != "" && !headerContainsToken(header, "Vary", key) {
+				header.Add("Vary", key)
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package gorest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCachePolicyString verifies the Cache-Control header values.
+func TestCachePolicyString(t *testing.T) {
+	tests := []struct {
+		policy   CachePolicy
+		expected string
+	}{
+		{defaultCachePolicy, "private, max-age=0, must-revalidate"},
+		{CachePolicy{NoStore: true, Public: true, MaxAge: time.Hour}, "no-store"},
+		{CachePolicy{Public: true, MaxAge: time.Hour, SMaxAge: 2 * time.Hour, StaleWhileRevalidate: time.Minute, StaleIfError: time.Hour, Immutable: true},
+			"public, max-age=3600, s-maxage=7200, stale-while-revalidate=60, stale-if-error=3600, immutable"},
+		{CachePolicy{Private: true, NoCache: true}, "private, no-cache, max-age=0"},
+	}
+
+	for _, test := range tests {
+		if value := test.policy.String(); value != test.expected {
+			t.Fatalf("Unexpected Cache-Control. Expected: %s - Found: %s.", test.expected, value)
+		}
+	}
+}
+
+// serveCachePolicy serves a GET request to a route with provided policies.
+func serveCachePolicy(routePolicy, responsePolicy *CachePolicy, headers http.Header) http.Header {
+	h := New()
+	route := NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		response := NewStandardResponse()
+		response.SetBody([]byte("body"))
+		response.SetHeaders(headers)
+		if responsePolicy != nil {
+			response.SetCachePolicy(*responsePolicy)
+		}
+		return http.StatusOK, response
+	}), "/")
+	if routePolicy != nil {
+		route.SetCachePolicy(*routePolicy)
+	}
+	h.RegisterRoute(route)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w.Result().Header
+}
+
+// TestCachePolicyPrecedence verifies that the response policy overrides the
+// route one, which overrides the default one, and that the Cache-Control
+// header set by the response is kept.
+func TestCachePolicyPrecedence(t *testing.T) {
+	routePolicy := &CachePolicy{Public: true, MaxAge: time.Minute, Vary: []string{"Authorization"}}
+	responsePolicy := &CachePolicy{NoStore: true}
+
+	tests := []struct {
+		name     string
+		route    *CachePolicy
+		response *CachePolicy
+		headers  http.Header
+		expected string
+	}{
+		{"default", nil, nil, nil, "private, max-age=0, must-revalidate"},
+		{"route", routePolicy, nil, nil, "public, max-age=60"},
+		{"response", routePolicy, responsePolicy, nil, "no-store"},
+		{"header", routePolicy, responsePolicy, http.Header{"Cache-Control": {"max-age=5"}}, "max-age=5"},
+	}
+
+	for _, test := range tests {
+		headers := serveCachePolicy(test.route, test.response, test.headers)
+		if value := headers.Get("Cache-Control"); value != test.expected {
+			t.Fatalf("Unexpected Cache-Control for %s. Expected: %s - Found: %s.", test.name, test.expected, value)
+		}
+	}
+
+	headers := serveCachePolicy(routePolicy, nil, http.Header{"Vary": {"Accept-Language"}})
+	if vary := headers["Vary"]; len(vary) != 2 || vary[0] != "Accept-Language" || vary[1] != "Authorization" {
+		t.Fatalf("Unexpected Vary. Expected: [Accept-Language Authorization] - Found: %v.", vary)
+	}
+}
+
+// TestCachePolicyExpires verifies the Expires header.
+func TestCachePolicyExpires(t *testing.T) {
+	headers := serveCachePolicy(&CachePolicy{Public: true, MaxAge: time.Hour, Expires: true}, nil, nil)
+	expires, err := http.ParseTime(headers.Get("Expires"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	if delta := time.Until(expires); delta < 59*time.Minute || delta > time.Hour {
+		t.Fatalf("Unexpected Expires. Expected: in an hour - Found: %s.", expires)
+	}
+
+	headers = serveCachePolicy(&CachePolicy{NoStore: true, Expires: true}, nil, nil)
+	if expires := headers.Get("Expires"); expires != "0" {
+		t.Fatalf("Unexpected Expires. Expected: 0 - Found: %s.", expires)
+	}
+}
+
+// TestCachePolicyOnlySuccessfulReads verifies that the policy applies only
+// to the successful GET and HEAD responses.
+func TestCachePolicyOnlySuccessfulReads(t *testing.T) {
+	h := New()
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		return http.StatusNotFound, nil
+	}), "/").SetCachePolicy(CachePolicy{Public: true, MaxAge: time.Hour}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if value := w.Result().Header.Get("Cache-Control"); value != "" {
+		t.Fatalf("Unexpected Cache-Control. Expected: '' - Found: %s.", value)
+	}
+}
+
+// TestCachePolicyFilesAndStreams verifies that the policy of the route
+// applies to the files and the streamed bodies too.
+func TestCachePolicyFilesAndStreams(t *testing.T) {
+	policy := CachePolicy{Public: true, MaxAge: time.Hour}
+	responses := map[string]func() Response{
+		"file": func() Response {
+			return NewFileResponse(strings.NewReader("body"), "body.txt", 4, time.Time{})
+		},
+		"stream": func() Response {
+			return NewStreamResponse(strings.NewReader("body"))
+		},
+	}
+
+	for name, newResponse := range responses {
+		h := New()
+		h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+			return http.StatusOK, newResponse()
+		}), "/").SetCachePolicy(policy))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if value := w.Result().Header.Get("Cache-Control"); value != "public, max-age=3600" {
+			t.Fatalf("Unexpected Cache-Control for %s. Expected: public, max-age=3600 - Found: %s.", name, value)
+		}
+		if body := w.Body.String(); body != "body" {
+			t.Fatalf("Unexpected body for %s. Expected: body - Found: %s.", name, body)
+		}
+	}
+}
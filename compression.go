@@ -0,0 +1,186 @@
+package gorest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// defaultCompressionMinSize is the default minimum size of the bodies to be
+// compressed, smaller ones are not worth it.
+const defaultCompressionMinSize = 1024
+
+// compressionEncodings are the supported content codings, in order of
+// preference.
+var compressionEncodings = []string{"gzip", "deflate"}
+
+// compressedMediaTypes are the media types whose content is already
+// compressed, the image, audio and video ones are compressed as well apart
+// from SVG images.
+var compressedMediaTypes = map[string]bool{
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/zip":              true,
+	"application/x-7z-compressed":  true,
+	"application/x-rar-compressed": true,
+	"application/x-bzip2":          true,
+	"application/zstd":             true,
+	"font/woff":                    true,
+	"font/woff2":                   true,
+}
+
+var gzipWriters = sync.Pool{New: func() interface{} {
+	return gzip.NewWriter(nil)
+}}
+
+// zlibWriters are used for the deflate content coding, which is the zlib
+// format according to RFC 9110.
+var zlibWriters = sync.Pool{New: func() interface{} {
+	return zlib.NewWriter(nil)
+}}
+
+// EnableCompression enables the gzip and deflate compression of the response
+// bodies of at least minSize bytes, according to the client Accept-Encoding
+// header; if minSize is not positive 1 KiB is used. Streamed bodies and files
+// are not compressed, as well as the already compressed media types.
+func (h *RestHandler) EnableCompression(minSize int) {
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+	h.compressionMinSize = minSize
+}
+
+// DisableCompression disables the response compression for the route.
+// The route itself is returned to allow chaining calls.
+func (r *Route) DisableCompression() *Route {
+	r.noCompression = true
+	r.resetRouters()
+	return r
+}
+
+// negotiateEncoding returns the content coding the body must be compressed
+// with, or an empty string if it must not be compressed. Vary is set whenever
+// the compression is enabled for the route, including on 304 Not Modified
+// responses.
+func (h *RestHandler) negotiateEncoding(w http.ResponseWriter, r *http.Request, route *Route, body []byte) string {
+	if h.compressionMinSize <= 0 || route.noCompression {
+		return ""
+	}
+	addVary(w.Header(), "Accept-Encoding")
+	if len(body) < h.compressionMinSize {
+		return ""
+	}
+	if w.Header().Get("Content-Encoding") != "" || isCompressedMediaType(w.Header().Get("Content-Type")) {
+		return ""
+	}
+
+	encodings := parseQualityValues(r.Header.Get("Accept-Encoding"))
+	best, bestQ := "", 0.0
+	for _, encoding := range compressionEncodings {
+		q := -1.0
+		for _, candidate := range encodings {
+			if candidate.value == encoding {
+				q = candidate.q
+				break
+			}
+			if candidate.value == "*" && q < 0 {
+				q = candidate.q
+			}
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// isCompressedMediaType verifies whether the content of the media type is
+// already compressed.
+func isCompressedMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if compressedMediaTypes[mediaType] {
+		return true
+	}
+	for _, prefix := range []string{"image/", "audio/", "video/"} {
+		if strings.HasPrefix(mediaType, prefix) {
+			return mediaType != "image/svg+xml"
+		}
+	}
+	return false
+}
+
+// compress compresses the body using the pooled writers.
+func compress(encoding string, body []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	var writer interface {
+		io.WriteCloser
+		Reset(io.Writer)
+	}
+	switch encoding {
+	case "gzip":
+		gz := gzipWriters.Get().(*gzip.Writer)
+		defer gzipWriters.Put(gz)
+		writer = gz
+	case "deflate":
+		zl := zlibWriters.Get().(*zlib.Writer)
+		defer zlibWriters.Put(zl)
+		writer = zl
+	default:
+		return nil, fmt.Errorf("unsupported content coding %s", encoding)
+	}
+
+	writer.Reset(&buffer)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// setEncodingETag makes the strong ETag set by the response specific to the
+// content coding, since the compressed representation is not byte-for-byte
+// identical, appending the coding as the ETags computed by gorest do. The
+// suffix is ignored when the ETags are compared with the resource version.
+func setEncodingETag(header http.Header, encoding string) {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", strings.TrimSuffix(quoteETag(etag), `"`)+"-"+encoding+`"`)
+	}
+}
+
+// trimEncodingETag removes the content coding suffix from the quoted entity
+// tag, if any.
+func trimEncodingETag(tag string) string {
+	for _, encoding := range compressionEncodings {
+		if suffix := "-" + encoding + `"`; strings.HasSuffix(tag, suffix) {
+			return strings.TrimSuffix(tag, suffix) + `"`
+		}
+	}
+	return tag
+}
+
+// restoreEncodingETag sets, on a 304 Not Modified response, the ETag specific
+// to the content coding that the client validated with If-None-Match, which
+// is the one it received with the compressed representation.
+func restoreEncodingETag(header http.Header, r *http.Request) {
+	etag := header.Get("ETag")
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		return
+	}
+	for _, tag := range parseETags(r.Header.Get("If-None-Match")) {
+		if tag.tag != etag && trimEncodingETag(tag.tag) == etag {
+			header.Set("ETag", tag.tag)
+			return
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package gorest
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testLargeBody is a body large enough to be compressed.
+var testLargeBody = `{"data":"` + strings.Repeat("gorest", 500) + `"}`
+
+// serveCompressed serves a route returning the body with the compression
+// enabled.
+func serveCompressed(body string, acceptEncoding string, configure func(*Route)) *httptest.ResponseRecorder {
+	h := New()
+	h.EnableCompression(0)
+	route := NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		response := NewStandardResponse()
+		response.SetBody([]byte(body))
+		return http.StatusOK, response
+	}), "/")
+	if configure != nil {
+		configure(route)
+	}
+	h.RegisterRoute(route)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// TestCompressionNegotiation verifies that the body is compressed with the
+// encoding preferred by the client.
+func TestCompressionNegotiation(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		encoding       string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"*", "gzip"},
+		{"gzip;q=0, *;q=0.3", "deflate"},
+		{"br", ""},
+	}
+
+	for _, test := range tests {
+		w := serveCompressed(testLargeBody, test.acceptEncoding, nil)
+		res := w.Result()
+		if encoding := res.Header.Get("Content-Encoding"); encoding != test.encoding {
+			t.Fatalf("Unexpected Content-Encoding for %s. Expected: %s - Found: %s.", test.acceptEncoding, test.encoding, encoding)
+		}
+		if vary := res.Header.Get("Vary"); vary != "Accept-Encoding" {
+			t.Fatalf("Unexpected Vary. Expected: Accept-Encoding - Found: %s.", vary)
+		}
+
+		var reader io.Reader = w.Body
+		switch test.encoding {
+		case "gzip":
+			reader, _ = gzip.NewReader(w.Body)
+		case "deflate":
+			reader, _ = zlib.NewReader(w.Body)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil || string(body) != testLargeBody {
+			t.Fatalf("Unexpected body for %s. Found: %d bytes (%v).", test.acceptEncoding, len(body), err)
+		}
+
+		etag := res.Header.Get("ETag")
+		expected := getETag([]byte(testLargeBody))
+		if test.encoding != "" {
+			expected += "-" + test.encoding
+		}
+		if expected = quoteETag(expected); etag != expected {
+			t.Fatalf("Unexpected ETag. Expected: %s - Found: %s.", expected, etag)
+		}
+	}
+}
+
+// TestCompressionSkipped verifies that small bodies, compressed media types
+// and routes opting out are not compressed.
+func TestCompressionSkipped(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		configure func(*Route)
+	}{
+		{"small body", `{"a":1}`, nil},
+		{"compressed type", "\x89PNG\r\n\x1a\n" + strings.Repeat("x", 2000), nil},
+		{"opt-out", testLargeBody, func(r *Route) { r.DisableCompression() }},
+	}
+
+	for _, test := range tests {
+		w := serveCompressed(test.body, "gzip", test.configure)
+		if encoding := w.Result().Header.Get("Content-Encoding"); encoding != "" {
+			t.Fatalf("Unexpected Content-Encoding for %s. Expected: '' - Found: %s.", test.name, encoding)
+		}
+		if w.Body.String() != test.body {
+			t.Fatalf("Unexpected body for %s.", test.name)
+		}
+	}
+}
+
+// TestCompressionETag verifies that the strong ETags set by the responses
+// become specific to the encoding when the body is compressed, and that
+// conditional requests use the encoding-specific ETag.
+func TestCompressionETag(t *testing.T) {
+	h := New()
+	h.EnableCompression(10)
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		response := NewStandardResponse()
+		response.SetBody([]byte(testLargeBody))
+		response.SetHeaders(http.Header{"Etag": {`"v1"`}})
+		return http.StatusOK, response
+	}), "/"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if etag := w.Result().Header.Get("ETag"); etag != `"v1-gzip"` {
+		t.Fatalf("Unexpected ETag. Expected: \"v1-gzip\" - Found: %s.", etag)
+	}
+
+	etag := serveCompressed(testLargeBody, "gzip", nil).Result().Header.Get("ETag")
+	h = New()
+	h.EnableCompression(0)
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		response := NewStandardResponse()
+		response.SetBody([]byte(testLargeBody))
+		return http.StatusOK, response
+	}), "/"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusNotModified, w.Code)
+	}
+}
+
+// TestCompressionVersionedResource verifies that the ETag of a compressed
+// representation of a versioned resource can be used in the conditional
+// requests, including the If-Match ones of the updates.
+func TestCompressionVersionedResource(t *testing.T) {
+	res := &testVersionedResource{version: Version{ETag: "v1"}}
+	h := New()
+	h.EnableCompression(1)
+	h.RegisterRoute(NewRoute(res, "/"))
+
+	serve := func(method string, headers map[string]string) *http.Response {
+		req := httptest.NewRequest(method, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	res1 := serve(http.MethodGet, nil)
+	etag := res1.Header.Get("ETag")
+	if res1.Header.Get("Content-Encoding") != "gzip" || etag != `"v1-gzip"` {
+		t.Fatalf("Unexpected ETag. Expected: \"v1-gzip\" - Found: %s.", etag)
+	}
+
+	res2 := serve(http.MethodGet, map[string]string{"If-None-Match": etag})
+	if res2.StatusCode != http.StatusNotModified {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusNotModified, res2.StatusCode)
+	}
+	if found := res2.Header.Get("ETag"); found != etag {
+		t.Fatalf("Unexpected ETag. Expected: %s - Found: %s.", etag, found)
+	}
+	if vary := res2.Header.Get("Vary"); vary != "Accept-Encoding" {
+		t.Fatalf("Unexpected Vary. Expected: Accept-Encoding - Found: %s.", vary)
+	}
+
+	res3 := serve(http.MethodPut, map[string]string{"If-Match": etag})
+	if res3.StatusCode != http.StatusOK || !res.updated {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, res3.StatusCode)
+	}
+}
@@ -0,0 +1,250 @@
+package gorest
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxMemory is the maximum amount of memory used to parse multipart
+// forms, the remaining parts are stored in temporary files.
+const defaultMaxMemory = 32 << 20
+
+// multipartFormsContextKey is the context key used to collect the multipart
+// forms parsed while serving a request.
+type multipartFormsContextKey struct{}
+
+// ErrUnsupportedMediaType is returned when the request body media type is not
+// supported.
+var ErrUnsupportedMediaType = NewError(http.StatusUnsupportedMediaType, "unsupported media type")
+
+// Decoder decodes the body of a request into the provided value.
+type Decoder interface {
+	Decode(r *http.Request, v interface{}) error
+}
+
+// DecoderFunc is an adapter to allow the use of ordinary functions as Decoder.
+type DecoderFunc func(r *http.Request, v interface{}) error
+
+// Decode calls f(r, v).
+func (f DecoderFunc) Decode(r *http.Request, v interface{}) error {
+	return f(r, v)
+}
+
+// DecodeError is returned when the request body is malformed.
+type DecodeError struct {
+	MediaType string       // Media type of the request body.
+	Fields    []FieldError // Invalid fields, if they can be identified.
+	Err       error        // Underlying decoding error.
+}
+
+// Error returns the description of the decoding error.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("malformed %s request body: %s", e.MediaType, e.Err.Error())
+}
+
+// Unwrap returns the underlying decoding error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// PayloadSupported is the interface implemented by the resources that want
+// their request body decoded, and validated, by gorest before being invoked.
+// NewPayload returns a pointer to the value the body of requests with provided
+// method must be decoded into, or nil if the body must not be decoded.
+// The decoded value is available through GetPayload and Request.Payload.
+type PayloadSupported interface {
+	NewPayload(method string) interface{}
+}
+
+// defaultDecoders are the decoders available in every RestHandler.
+var defaultDecoders = map[string]Decoder{
+	"application/json":                  DecoderFunc(decodeJSON),
+	"application/xml":                   DecoderFunc(decodeXML),
+	"text/xml":                          DecoderFunc(decodeXML),
+	"application/x-www-form-urlencoded": DecoderFunc(decodeForm),
+	"multipart/form-data":               DecoderFunc(decodeMultipart),
+}
+
+// RegisterDecoder registers the Decoder for the request bodies with provided
+// media type, replacing the default one if any. JSON, XML, URL-encoded and
+// multipart forms are supported by default.
+func (h *RestHandler) RegisterDecoder(mediaType string, decoder Decoder) {
+	if h.decoders == nil {
+		h.decoders = make(map[string]Decoder)
+	}
+	h.decoders[strings.ToLower(mediaType)] = decoder
+}
+
+// getDecoder returns the Decoder for the media type, media types with the
+// "+json" and "+xml" structured syntax suffixes fall back to the JSON and
+// XML decoders.
+func (h *RestHandler) getDecoder(mediaType string) Decoder {
+	candidates := []string{mediaType}
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		candidates = append(candidates, "application/"+mediaType[i+1:])
+	}
+
+	for _, candidate := range candidates {
+		if decoder, ok := h.decoders[candidate]; ok {
+			return decoder
+		}
+		if decoder, ok := defaultDecoders[candidate]; ok {
+			return decoder
+		}
+	}
+	return nil
+}
+
+// Decode decodes the request body into v choosing the Decoder based on the
+// request Content-Type, among the ones registered in the RestHandler serving
+// the request. It returns ErrUnsupportedMediaType if no Decoder is available
+// and a DecodeError if the body is malformed.
+func Decode(r *http.Request, v interface{}) error {
+	h := servingHandler(r)
+	if h == nil {
+		h = &RestHandler{}
+	}
+	return h.decode(r, v)
+}
+
+// decode decodes the request body into v.
+func (h *RestHandler) decode(r *http.Request, v interface{}) error {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return ErrUnsupportedMediaType
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ErrUnsupportedMediaType
+	}
+
+	decoder := h.getDecoder(mediaType)
+	if decoder == nil {
+		return Errorf(http.StatusUnsupportedMediaType, "unsupported media type %s", mediaType)
+	}
+
+	if err := decoder.Decode(r, v); err != nil {
+		if errors.Is(err, errLimitExceeded) {
+			return ErrRequestTooLarge
+		}
+		var gorestErr *Error
+		var decodeErr *DecodeError
+		if errors.As(err, &gorestErr) || errors.As(err, &decodeErr) {
+			return err
+		}
+		return &DecodeError{MediaType: mediaType, Fields: decodeFieldErrors(err), Err: err}
+	}
+	return nil
+}
+
+// payloadHandler wraps the resource handler decoding the request body into
+// the payload provided by the resource, if it implements PayloadSupported,
+// and validating it.
+func (h *RestHandler) payloadHandler(handler Handler, resource Resource, method string) Handler {
+	res, ok := resource.(PayloadSupported)
+	if !ok {
+		return handler
+	}
+
+	return func(r *http.Request) (int, Response) {
+		payload := res.NewPayload(method)
+		if payload == nil {
+			return handler(r)
+		}
+		if err := h.decode(r, payload); err != nil {
+			return h.mapError(r, err)
+		}
+		if err := h.getValidator().Validate(payload); err != nil {
+			return h.mapError(r, err)
+		}
+		return handler(WithPayload(r, payload))
+	}
+}
+
+// decodeJSON decodes a JSON request body.
+func decodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		if err == io.EOF {
+			return errors.New("empty body")
+		}
+		return err
+	}
+	return nil
+}
+
+// decodeXML decodes a XML request body.
+func decodeXML(r *http.Request, v interface{}) error {
+	if err := xml.NewDecoder(r.Body).Decode(v); err != nil {
+		if err == io.EOF {
+			return errors.New("empty body")
+		}
+		return err
+	}
+	return nil
+}
+
+// decodeForm decodes a URL-encoded form request body, reusing the values
+// already parsed by handleRoute.
+func decodeForm(r *http.Request, v interface{}) error {
+	if r.PostForm == nil {
+		if err := r.ParseForm(); err != nil {
+			return err
+		}
+	}
+	return decodeValues(r.PostForm, nil, v)
+}
+
+// decodeMultipart decodes a multipart form request body.
+func decodeMultipart(r *http.Request, v interface{}) error {
+	if r.MultipartForm == nil {
+		if err := r.ParseMultipartForm(defaultMaxMemory); err != nil {
+			return err
+		}
+		if forms, ok := r.Context().Value(multipartFormsContextKey{}).(*[]*multipart.Form); ok {
+			*forms = append(*forms, r.MultipartForm)
+		}
+	}
+	return decodeValues(r.MultipartForm.Value, r.MultipartForm.File, v)
+}
+
+// trackMultipartForms returns a copy of the request collecting the multipart
+// forms parsed by gorest, along with the function removing their temporary
+// files once the request has been served: net/http only removes the ones of
+// the original request, while the forms are parsed on its copies.
+func trackMultipartForms(r *http.Request) (*http.Request, func()) {
+	forms := new([]*multipart.Form)
+	r = r.WithContext(context.WithValue(r.Context(), multipartFormsContextKey{}, forms))
+	return r, func() {
+		if r.MultipartForm != nil {
+			*forms = append(*forms, r.MultipartForm)
+		}
+		for _, form := range *forms {
+			form.RemoveAll()
+		}
+	}
+}
+
+// decodeFieldErrors extracts the invalid fields from the decoding error.
+func decodeFieldErrors(err error) []FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Pointer: "/" + strings.Replace(typeErr.Field, ".", "/", -1),
+			Message: "must be of type " + typeErr.Type.String(),
+		}}
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return []FieldError{*fieldErr}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package gorest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testPayload is the payload used by the decoding tests.
+type testPayload struct {
+	Name string `json:"name" xml:"name" form:"name"`
+	Age  int    `json:"age" xml:"age" form:"age"`
+}
+
+// testPayloadResource decodes the POST request bodies into testPayload.
+type testPayloadResource struct {
+	payload interface{}
+}
+
+func (t *testPayloadResource) NewPayload(method string) interface{} {
+	if method != http.MethodPost {
+		return nil
+	}
+	return &testPayload{}
+}
+
+func (t *testPayloadResource) Post(r *http.Request) (int, Response) {
+	t.payload = GetPayload(r)
+	return http.StatusOK, nil
+}
+
+func (t *testPayloadResource) Delete(r *http.Request) (int, Response) {
+	t.payload = GetPayload(r)
+	return http.StatusOK, nil
+}
+
+// newMultipartBody creates a multipart body with provided fields.
+func newMultipartBody(t *testing.T, fields map[string]string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for key, value := range fields {
+		if err := mw.WriteField(key, value); err != nil {
+			t.Fatalf("Unexpected error: %s.", err.Error())
+		}
+	}
+	mw.Close()
+	return &body, mw.FormDataContentType()
+}
+
+// TestDecodeContentTypes verifies that the body is decoded according to the
+// request Content-Type.
+func TestDecodeContentTypes(t *testing.T) {
+	multipartBody, multipartType := newMultipartBody(t, map[string]string{"name": "fred", "age": "33"})
+
+	tests := []struct {
+		contentType string
+		body        string
+	}{
+		{"application/json", `{"name":"fred","age":33}`},
+		{"application/json; charset=utf-8", `{"name":"fred","age":33}`},
+		{"application/vnd.gorest+json", `{"name":"fred","age":33}`},
+		{"application/xml", `<testPayload><name>fred</name><age>33</age></testPayload>`},
+		{"text/xml", `<testPayload><name>fred</name><age>33</age></testPayload>`},
+		{"application/x-www-form-urlencoded", `name=fred&age=33`},
+		{multipartType, multipartBody.String()},
+	}
+
+	for _, test := range tests {
+		res := &testPayloadResource{}
+		h := New()
+		h.RegisterRoute(NewRoute(res, "/"))
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+		req.Header.Set("Content-Type", test.contentType)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d (%s).", test.contentType, http.StatusOK, w.Code, w.Body.String())
+		}
+
+		payload, ok := res.payload.(*testPayload)
+		if !ok {
+			t.Fatalf("Unexpected payload for %s: %+v.", test.contentType, res.payload)
+		}
+		if payload.Name != "fred" || payload.Age != 33 {
+			t.Fatalf("Unexpected payload for %s. Expected: %+v - Found: %+v.", test.contentType, testPayload{"fred", 33}, *payload)
+		}
+	}
+}
+
+// TestDecodeMultipartTemporaryFiles verifies that the temporary files of the
+// multipart forms are removed once the request has been served.
+func TestDecodeMultipartTemporaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("name", "fred")
+	part, err := mw.CreateFormFile("attachment", "attachment.bin")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	part.Write(make([]byte, defaultMaxMemory+1))
+	mw.Close()
+
+	res := &testPayloadResource{}
+	h := New()
+	h.RegisterRoute(NewRoute(res, "/"))
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d (%s).", http.StatusOK, w.Code, w.Body.String())
+	}
+	if payload, ok := res.payload.(*testPayload); !ok || payload.Name != "fred" {
+		t.Fatalf("Unexpected payload: %+v.", res.payload)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	if len(files) != 0 {
+		t.Fatalf("Unexpected temporary files. Expected: 0 - Found: %d.", len(files))
+	}
+}
+
+// TestDecodeFailures verifies the 415 and 400 responses.
+func TestDecodeFailures(t *testing.T) {
+	res := &testPayloadResource{}
+	h := New()
+	h.RegisterRoute(NewRoute(res, "/"))
+
+	tests := []struct {
+		contentType string
+		body        string
+		code        int
+		response    string // Expected body suffix.
+	}{
+		{"", `{}`, http.StatusUnsupportedMediaType, `{"status":"NAK","message":"unsupported media type"}`},
+		{"text/csv", `a,b`, http.StatusUnsupportedMediaType, `{"status":"NAK","message":"unsupported media type text/csv"}`},
+		{"application/json", ``, http.StatusBadRequest, `{"status":"NAK","message":"malformed application/json request body: empty body"}`},
+		{"application/json", `{"name":`, http.StatusBadRequest, `{"status":"NAK","message":"malformed application/json request body: unexpected EOF"}`},
+		{
+			"application/json", `{"name":"fred","age":"old"}`, http.StatusBadRequest,
+			`"errors":[{"pointer":"/age","message":"must be of type int"}]}`,
+		},
+		{
+			"application/x-www-form-urlencoded", `age=old`, http.StatusBadRequest,
+			`{"status":"NAK","message":"malformed application/x-www-form-urlencoded request body: /age: must be an integer","errors":[{"pointer":"/age","message":"must be an integer"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		res.payload = nil
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+		if test.contentType != "" {
+			req.Header.Set("Content-Type", test.contentType)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != test.code {
+			t.Fatalf("Unexpected status code for %q. Expected: %d - Found: %d.", test.body, test.code, w.Code)
+		}
+		if !strings.HasSuffix(w.Body.String(), test.response) {
+			t.Fatalf("Unexpected body for %q. Expected: %s - Found: %s.", test.body, test.response, w.Body.String())
+		}
+		if res.payload != nil {
+			t.Fatalf("The resource should not have been invoked.")
+		}
+	}
+
+	// Methods without payload are not decoded.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+}
+
+// TestRegisterDecoder verifies that custom decoders can be registered.
+func TestRegisterDecoder(t *testing.T) {
+	h := New()
+	h.RegisterDecoder("Text/Plain", DecoderFunc(func(r *http.Request, v interface{}) error {
+		v.(*testPayload).Name = "plain"
+		return nil
+	}))
+	h.RegisterDecoder("application/json", DecoderFunc(func(r *http.Request, v interface{}) error {
+		return errors.New("json disabled")
+	}))
+
+	var payload testPayload
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("whatever"))
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if err := h.decode(req, &payload); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	if payload.Name != "plain" {
+		t.Fatalf("Unexpected name. Expected: %s - Found: %s.", "plain", payload.Name)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	var decodeErr *DecodeError
+	if err := h.decode(req, &payload); !errors.As(err, &decodeErr) {
+		t.Fatalf("Unexpected error. Expected DecodeError - Found: %v.", err)
+	}
+}
+
+// TestDecodeFunction verifies the Decode function used by the resources.
+func TestDecodeFunction(t *testing.T) {
+	var decoded testPayload
+	var decodeErr error
+	h := New()
+	h.RegisterRoute(NewRoute(testErrPostResource(func(r *http.Request) (Response, error) {
+		decodeErr = Decode(r, &decoded)
+		return nil, decodeErr
+	}), "/"))
+
+	body, _ := json.Marshal(testPayload{"fred", 33})
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if decodeErr != nil {
+		t.Fatalf("Unexpected error: %s.", decodeErr.Error())
+	}
+	if decoded.Name != "fred" || decoded.Age != 33 {
+		t.Fatalf("Unexpected payload. Expected: %+v - Found: %+v.", testPayload{"fred", 33}, decoded)
+	}
+
+	// Outside gorest the default decoders are used.
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=monika"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := Decode(req, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	if decoded.Name != "monika" {
+		t.Fatalf("Unexpected name. Expected: %s - Found: %s.", "monika", decoded.Name)
+	}
+}
+
+// testErrPostResource is a resource serving POST requests with the wrapped
+// error-returning function.
+type testErrPostResource func(r *http.Request) (Response, error)
+
+func (f testErrPostResource) PostErr(r *http.Request) (Response, error) {
+	return f(r)
+}
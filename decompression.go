@@ -0,0 +1,81 @@
+package gorest
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxDecompressedSize is the default maximum size of the decompressed
+// request bodies.
+const defaultMaxDecompressedSize = 10 << 20
+
+// SetMaxDecompressedSize sets the maximum size of the request bodies once
+// decompressed, protecting against decompression bombs; 413 Request Entity
+// Too Large is returned when exceeded. It defaults to 10 MiB.
+func (h *RestHandler) SetMaxDecompressedSize(size int64) {
+	h.maxDecompressedSize = size
+}
+
+// decompressBody transparently decodes the request body according to its
+// Content-Encoding, gzip and deflate are supported. It returns a 415 Error
+// for the unknown encodings.
+func (h *RestHandler) decompressBody(r *http.Request) error {
+	var codings []string
+	for _, value := range r.Header["Content-Encoding"] {
+		for _, coding := range strings.Split(value, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+	if len(codings) == 0 || r.Body == nil {
+		return nil
+	}
+
+	// The codings are listed in the order they were applied.
+	var reader io.Reader = r.Body
+	for i := len(codings) - 1; i >= 0; i-- {
+		var err error
+		switch codings[i] {
+		case "gzip", "x-gzip":
+			reader, err = gzip.NewReader(reader)
+		case "deflate":
+			reader, err = newDeflateReader(reader)
+		default:
+			return Errorf(http.StatusUnsupportedMediaType, "unsupported content encoding %s", codings[i])
+		}
+		if err != nil {
+			return Errorf(http.StatusBadRequest, "malformed %s request body", codings[i])
+		}
+	}
+
+	limit := h.maxDecompressedSize
+	if limit <= 0 {
+		limit = defaultMaxDecompressedSize
+	}
+	r.Body = &limitedBody{countingReader{reader: reader, limit: limit}, r.Body}
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	return nil
+}
+
+// newDeflateReader returns the reader of a deflate body, which should be in
+// the zlib format but is sent as raw deflate data by some clients.
+func newDeflateReader(reader io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(reader)
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
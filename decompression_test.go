@@ -0,0 +1,109 @@
+package gorest
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// compressBody compresses the data with the writer created by the function.
+func compressBody(data string, newWriter func(io.Writer) io.WriteCloser) *bytes.Buffer {
+	var buffer bytes.Buffer
+	writer := newWriter(&buffer)
+	io.WriteString(writer, data)
+	writer.Close()
+	return &buffer
+}
+
+func newGzipWriter(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+func newZlibWriter(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+func newFlateWriter(w io.Writer) io.WriteCloser {
+	writer, _ := flate.NewWriter(w, flate.DefaultCompression)
+	return writer
+}
+
+// postCompressed posts the body with provided Content-Encoding to a route
+// decoding testPayload.
+func postCompressed(h *RestHandler, body io.Reader, encoding, contentType string) (*testPayloadResource, *httptest.ResponseRecorder) {
+	res := &testPayloadResource{}
+	h.RegisterRoute(NewRoute(res, "/"))
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Content-Encoding", encoding)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return res, w
+}
+
+// TestDecompressRequestBody verifies that the compressed bodies are decoded
+// before the payload decoding and the form parsing.
+func TestDecompressRequestBody(t *testing.T) {
+	json := `{"name":"fred","age":33}`
+	tests := []struct {
+		encoding string
+		body     io.Reader
+	}{
+		{"gzip", compressBody(json, newGzipWriter)},
+		{"deflate", compressBody(json, newZlibWriter)},
+		{"deflate", compressBody(json, newFlateWriter)},
+		{"identity", strings.NewReader(json)},
+		{"gzip, deflate", compressBody(compressBody(json, newGzipWriter).String(), newZlibWriter)},
+	}
+
+	for _, test := range tests {
+		res, w := postCompressed(New(), test.body, test.encoding, "application/json")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d.", test.encoding, http.StatusOK, w.Code)
+		}
+		if payload, ok := res.payload.(*testPayload); !ok || payload.Name != "fred" || payload.Age != 33 {
+			t.Fatalf("Unexpected payload for %s. Found: %+v.", test.encoding, res.payload)
+		}
+	}
+
+	res, w := postCompressed(New(), compressBody("name=fred&age=33", newGzipWriter), "gzip", "application/x-www-form-urlencoded")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if payload, ok := res.payload.(*testPayload); !ok || payload.Name != "fred" {
+		t.Fatalf("Unexpected form payload. Found: %+v.", res.payload)
+	}
+}
+
+// TestDecompressRequestBodyErrors verifies the unknown encodings, malformed
+// bodies and decompression bombs handling.
+func TestDecompressRequestBodyErrors(t *testing.T) {
+	_, w := postCompressed(New(), strings.NewReader("{}"), "br", "application/json")
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusUnsupportedMediaType, w.Code)
+	}
+	if accept := w.Result().Header.Get("Accept-Encoding"); accept != "gzip, deflate" {
+		t.Fatalf("Unexpected Accept-Encoding. Expected: gzip, deflate - Found: %s.", accept)
+	}
+
+	_, w = postCompressed(New(), strings.NewReader("not gzip"), "gzip", "application/json")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusBadRequest, w.Code)
+	}
+
+	bomb := `{"name":"` + strings.Repeat("a", 10000) + `"}`
+	h := New()
+	h.SetMaxDecompressedSize(1000)
+	_, w = postCompressed(h, compressBody(bomb, newGzipWriter), "gzip", "application/json")
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusRequestEntityTooLarge, w.Code)
+	}
+
+	h = New()
+	h.SetMaxDecompressedSize(1000)
+	_, w = postCompressed(h, compressBody("name="+strings.Repeat("a", 10000), newGzipWriter), "gzip", "application/x-www-form-urlencoded")
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusRequestEntityTooLarge, w.Code)
+	}
+}
@@ -0,0 +1,237 @@
+package gorest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ErrUnsupportedValue is returned by the encoders unable to encode a value,
+// in which case the next acceptable encoder is tried.
+var ErrUnsupportedValue = errors.New("value not supported by the encoder")
+
+// Encoder encodes the value of a ValueResponse into the writer.
+type Encoder interface {
+	Encode(w io.Writer, v interface{}) error
+}
+
+// EncoderFunc is an adapter to allow the use of ordinary functions as Encoder.
+type EncoderFunc func(w io.Writer, v interface{}) error
+
+// Encode calls f(w, v).
+func (f EncoderFunc) Encode(w io.Writer, v interface{}) error {
+	return f(w, v)
+}
+
+// encoderEntry is an Encoder registered for a media type.
+type encoderEntry struct {
+	mediaType   string // Media type used in the negotiation.
+	contentType string // Content-Type of the encoded body.
+	encoder     Encoder
+}
+
+// defaultEncoders are the encoders available in every RestHandler, in order
+// of preference.
+var defaultEncoders = []encoderEntry{
+	{"application/json", "application/json; charset=UTF-8", EncoderFunc(encodeJSON)},
+	{"application/xml", "application/xml; charset=UTF-8", EncoderFunc(encodeXML)},
+	{"text/plain", "text/plain; charset=UTF-8", EncoderFunc(encodeText)},
+	{"text/csv", "text/csv; charset=UTF-8", EncoderFunc(encodeCSV)},
+	{"application/x-ndjson", "application/x-ndjson", EncoderFunc(encodeNDJSON)},
+}
+
+// Negotiable is the interface implemented by the responses carrying a Go
+// value, instead of a pre-encoded body, that gorest encodes in the media type
+// negotiated with the client using the Accept header.
+type Negotiable interface {
+	GetValue() interface{}
+}
+
+// RegisterEncoder registers the Encoder for the media type, replacing the
+// existing one if any, with the provided Content-Type for the encoded bodies.
+// Registered encoders are preferred to the default ones (JSON, XML, plain
+// text, CSV and NDJSON) when the client accepts them with the same quality.
+func (h *RestHandler) RegisterEncoder(mediaType, contentType string, encoder Encoder) {
+	mediaType = strings.ToLower(mediaType)
+	entry := encoderEntry{mediaType: mediaType, contentType: contentType, encoder: encoder}
+	h.encoders = mergeEncoders([]encoderEntry{entry}, h.encoders)
+}
+
+// getEncoders returns the encoders of the handler in order of preference.
+func (h *RestHandler) getEncoders() []encoderEntry {
+	if h.encoders == nil {
+		return defaultEncoders
+	}
+	return mergeEncoders(h.encoders, defaultEncoders)
+}
+
+// mergeEncoders returns the preferred encoders followed by the other ones
+// registered for different media types.
+func mergeEncoders(preferred, others []encoderEntry) []encoderEntry {
+	encoders := append([]encoderEntry{}, preferred...)
+	for _, other := range others {
+		replaced := false
+		for _, entry := range preferred {
+			replaced = replaced || entry.mediaType == other.mediaType
+		}
+		if !replaced {
+			encoders = append(encoders, other)
+		}
+	}
+	return encoders
+}
+
+// encode encodes the value in the media type negotiated with the Accept
+// header, returning the body and its Content-Type. It returns a 406 Not
+// Acceptable Error if no encoder is acceptable.
+func (h *RestHandler) encode(r *http.Request, value interface{}) ([]byte, string, error) {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		accept = "*/*"
+	}
+	ranges := parseQualityValues(accept)
+
+	// Sort the encoders by the client preference, keeping the handler one
+	// for equal qualities.
+	var candidates []encoderEntry
+	var qualities []float64
+	for _, entry := range h.getEncoders() {
+		q := mediaRangeQuality(ranges, entry.mediaType)
+		if q <= 0 {
+			continue
+		}
+		i := len(candidates)
+		for i > 0 && qualities[i-1] < q {
+			i--
+		}
+		candidates = append(candidates[:i], append([]encoderEntry{entry}, candidates[i:]...)...)
+		qualities = append(qualities[:i], append([]float64{q}, qualities[i:]...)...)
+	}
+
+	for _, entry := range candidates {
+		var buffer bytes.Buffer
+		err := entry.encoder.Encode(&buffer, value)
+		if err == ErrUnsupportedValue {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return buffer.Bytes(), entry.contentType, nil
+	}
+	return nil, "", NewError(http.StatusNotAcceptable, "none of the accepted media types can be produced")
+}
+
+// encodeJSON encodes the value as JSON.
+func encodeJSON(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// encodeXML encodes the value as XML.
+func encodeXML(w io.Writer, v interface{}) error {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return ErrUnsupportedValue
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// encodeText encodes strings, byte slices, errors and fmt.Stringer values as
+// plain text, any other value is formatted with fmt.
+func encodeText(w io.Writer, v interface{}) error {
+	var err error
+	switch value := v.(type) {
+	case []byte:
+		_, err = w.Write(value)
+	case error:
+		_, err = io.WriteString(w, value.Error())
+	default:
+		_, err = fmt.Fprint(w, value)
+	}
+	return err
+}
+
+// encodeCSV encodes [][]string values and slices of structs as CSV, the
+// header row of the structs is made of the field names from the json tags.
+func encodeCSV(w io.Writer, v interface{}) error {
+	records, ok := v.([][]string)
+	if !ok {
+		var err error
+		if records, err = structRecords(v); err != nil {
+			return err
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// structRecords converts a slice of structs into CSV records.
+func structRecords(v interface{}) ([][]string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, ErrUnsupportedValue
+	}
+	rt := rv.Type().Elem()
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil, ErrUnsupportedValue
+	}
+
+	var indexes []int
+	var header []string
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if name := fieldName(field, "csv"); field.PkgPath == "" && name != "-" {
+			indexes = append(indexes, i)
+			header = append(header, name)
+		}
+	}
+
+	records := [][]string{header}
+	for i := 0; i < rv.Len(); i++ {
+		item := reflect.Indirect(rv.Index(i))
+		record := make([]string, len(indexes))
+		if item.IsValid() {
+			for j, index := range indexes {
+				record[j] = fmt.Sprint(item.Field(index).Interface())
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// encodeNDJSON encodes slices as newline delimited JSON, one line per
+// element, any other value is encoded in a single line.
+func encodeNDJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return encoder.Encode(v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := encoder.Encode(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package gorest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testItem is the value encoded by the negotiation tests.
+type testItem struct {
+	Name string `json:"name" xml:"name"`
+	Age  int    `json:"age" xml:"age"`
+}
+
+// testValueResource returns a ValueResponse carrying the value.
+type testValueResource struct {
+	value interface{}
+}
+
+func (t *testValueResource) Get(r *http.Request) (int, Response) {
+	return http.StatusOK, NewValueResponse(t.value)
+}
+
+// TestValueResponseNegotiation verifies that the value is encoded in the
+// media type preferred by the client.
+func TestValueResponseNegotiation(t *testing.T) {
+	items := []testItem{{"fred", 33}, {"wilma", 31}}
+
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", "application/json; charset=UTF-8", `[{"name":"fred","age":33},{"name":"wilma","age":31}]`},
+		{"*/*", "application/json; charset=UTF-8", `[{"name":"fred","age":33},{"name":"wilma","age":31}]`},
+		{"application/xml", "application/xml; charset=UTF-8", `<testItem><name>fred</name><age>33</age></testItem><testItem><name>wilma</name><age>31</age></testItem>`},
+		{"text/csv", "text/csv; charset=UTF-8", "name,age\nfred,33\nwilma,31\n"},
+		{"application/x-ndjson", "application/x-ndjson", "{\"name\":\"fred\",\"age\":33}\n{\"name\":\"wilma\",\"age\":31}\n"},
+		{"text/*", "text/plain; charset=UTF-8", "[{fred 33} {wilma 31}]"},
+		{"application/json;q=0.5, text/csv", "text/csv; charset=UTF-8", "name,age\nfred,33\nwilma,31\n"},
+		{"application/*;q=0.8, application/xml;q=0.1, text/csv;q=0.2", "application/json; charset=UTF-8", `[{"name":"fred","age":33},{"name":"wilma","age":31}]`},
+	}
+
+	for _, test := range tests {
+		h := New()
+		h.RegisterRoute(NewRoute(&testValueResource{value: items}, "/"))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		res := w.Result()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d.", test.accept, http.StatusOK, res.StatusCode)
+		}
+		if contentType := res.Header.Get("Content-Type"); contentType != test.contentType {
+			t.Fatalf("Unexpected Content-Type for %s. Expected: %s - Found: %s.", test.accept, test.contentType, contentType)
+		}
+		if vary := res.Header.Get("Vary"); vary != "Accept" {
+			t.Fatalf("Unexpected Vary header. Expected: Accept - Found: %s.", vary)
+		}
+		if body := w.Body.String(); body != test.body {
+			t.Fatalf("Unexpected body for %s. Expected: %s - Found: %s.", test.accept, test.body, body)
+		}
+	}
+}
+
+// TestValueResponseNotAcceptable verifies that 406 Not Acceptable is returned
+// when no encoder matches the Accept header.
+func TestValueResponseNotAcceptable(t *testing.T) {
+	tests := []struct {
+		accept string
+		value  interface{}
+	}{
+		{"image/png", testItem{"fred", 33}},
+		{"application/json;q=0", testItem{"fred", 33}},
+		{"text/csv", testItem{"fred", 33}},
+	}
+
+	for _, test := range tests {
+		h := New()
+		h.RegisterRoute(NewRoute(&testValueResource{value: test.value}, "/"))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", test.accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotAcceptable {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d.", test.accept, http.StatusNotAcceptable, w.Code)
+		}
+		if vary := w.Result().Header.Get("Vary"); vary != "Accept" {
+			t.Fatalf("Unexpected Vary header. Expected: Accept - Found: %s.", vary)
+		}
+	}
+}
+
+// TestRegisterEncoder verifies that custom encoders are used and preferred to
+// the default ones.
+func TestRegisterEncoder(t *testing.T) {
+	h := New()
+	h.RegisterEncoder("application/vnd.gorest", "application/vnd.gorest", EncoderFunc(func(w io.Writer, v interface{}) error {
+		_, err := io.WriteString(w, "custom")
+		return err
+	}))
+	h.RegisterRoute(NewRoute(&testValueResource{value: "value"}, "/"))
+
+	for _, accept := range []string{"application/vnd.gorest", "*/*"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if contentType := w.Result().Header.Get("Content-Type"); contentType != "application/vnd.gorest" {
+			t.Fatalf("Unexpected Content-Type. Expected: application/vnd.gorest - Found: %s.", contentType)
+		}
+		if body := w.Body.String(); body != "custom" {
+			t.Fatalf("Unexpected body. Expected: custom - Found: %s.", body)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if body := w.Body.String(); body != `"value"` {
+		t.Fatalf("Unexpected body. Expected: \"value\" - Found: %s.", body)
+	}
+}
+
+// TestStandardResponseNotNegotiated verifies that the StandardResponse body
+// is written as is regardless of the Accept header.
+func TestStandardResponseNotNegotiated(t *testing.T) {
+	h := New()
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		response := NewStandardResponse()
+		response.SetJSONBody(testItem{"fred", 33})
+		return http.StatusOK, response
+	}), "/"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"name":"fred"`) {
+		t.Fatalf("Unexpected body. Expected: JSON - Found: %s.", body)
+	}
+}
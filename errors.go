@@ -0,0 +1,222 @@
+package gorest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// handlerContextKey is the context key used to store the RestHandler serving
+// a request.
+type handlerContextKey struct{}
+
+var (
+	// ErrNotFound is returned when the requested resource does not exist.
+	ErrNotFound = NewError(http.StatusNotFound, "not found")
+	// ErrConflict is returned when the request conflicts with the current
+	// state of the resource.
+	ErrConflict = NewError(http.StatusConflict, "conflict")
+	// ErrUnauthorized is returned when the request lacks valid credentials.
+	ErrUnauthorized = NewError(http.StatusUnauthorized, "unauthorized")
+	// ErrForbidden is returned when the credentials do not grant access to
+	// the resource.
+	ErrForbidden = NewError(http.StatusForbidden, "forbidden")
+)
+
+// Error is an error carrying the HTTP status code it must be mapped to.
+// Errors can be wrapped, e.g. using fmt.Errorf with the %w verb, and are
+// still recognized by the default ErrorMapper.
+type Error struct {
+	Code    int    // HTTP status code.
+	Message string // Message describing the error.
+}
+
+// NewError creates a new Error with provided status code and message.
+func NewError(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Errorf creates a new Error with provided status code composing the
+// message using provided format and variadic arguments.
+func Errorf(code int, format string, args ...interface{}) *Error {
+	return NewError(code, fmt.Sprintf(format, args...))
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// FieldError describes the error of a single field of a request payload.
+type FieldError struct {
+	Pointer string `json:"pointer"` // JSON pointer to the field.
+	Message string `json:"message"` // Description of the error.
+}
+
+// Error returns the description of the field error.
+func (e *FieldError) Error() string {
+	return e.Pointer + ": " + e.Message
+}
+
+// ValidationError is returned when a request payload is not valid, it lists
+// all the invalid fields.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error returns the description of all the field errors.
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Error())
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// ValidationResponse is the NAK SimpleResponse produced for ValidationErrors,
+// listing all the invalid fields.
+type ValidationResponse struct {
+	SimpleResponse
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// GetBody returns the JSON encoding of the ValidationResponse.
+func (v ValidationResponse) GetBody() ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// ErrorMapper converts the errors returned by the resources into the status
+// code and the Response to be sent.
+type ErrorMapper interface {
+	MapError(r *http.Request, err error) (int, Response)
+}
+
+// ErrorMapperFunc is an adapter to allow the use of ordinary functions as
+// ErrorMapper.
+type ErrorMapperFunc func(r *http.Request, err error) (int, Response)
+
+// MapError calls f(r, err).
+func (f ErrorMapperFunc) MapError(r *http.Request, err error) (int, Response) {
+	return f(r, err)
+}
+
+// DefaultErrorMapper is the ErrorMapper used when none has been set, it maps
+// Error, DecodeError and ValidationError to the proper status code with a NAK
+// SimpleResponse body, or a ProblemResponse if enabled on the handler. Only the
+// message of the mapped error is sent, not the one of the errors wrapping it.
+// ParamError is mapped to 404 Not Found, as the requests whose parameters do
+// not satisfy the route constraints, e.g. an int parameter overflowing the
+// type. Any other error is mapped to 500 Internal Server Error without
+// disclosing its message.
+var DefaultErrorMapper ErrorMapper = ErrorMapperFunc(defaultMapError)
+
+func defaultMapError(r *http.Request, err error) (int, Response) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return errorResponse(r, http.StatusUnprocessableEntity, "validation failed", validationErr.Fields)
+	}
+
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		return errorResponse(r, http.StatusBadRequest, decodeErr.Error(), decodeErr.Fields)
+	}
+
+	var gorestErr *Error
+	if errors.As(err, &gorestErr) {
+		return errorResponse(r, gorestErr.Code, gorestErr.Message, nil)
+	}
+
+	var paramErr *ParamError
+	if errors.As(err, &paramErr) {
+		return errorResponse(r, ErrNotFound.Code, ErrNotFound.Message, nil)
+	}
+
+	return errorResponse(r, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+}
+
+// errorResponse creates the Response for an error, that is a ProblemResponse
+// if enabled on the handler serving the request or a NAK SimpleResponse
+// otherwise. The field errors, if any, are listed in the "errors" member.
+func errorResponse(r *http.Request, code int, message string, fields []FieldError) (int, Response) {
+	if h := servingHandler(r); h != nil && isEnabled(h.problemDetails) {
+		problem := NewProblemResponse(code, message)
+		problem.Instance = r.URL.Path
+		if len(fields) > 0 {
+			problem.Extensions = map[string]interface{}{"errors": fields}
+		}
+		return code, problem
+	}
+
+	if len(fields) > 0 {
+		return code, ValidationResponse{SimpleResponse: NewFailResponse(message), Errors: fields}
+	}
+	return code, NewFailResponse(message)
+}
+
+// SetErrorMapper sets the ErrorMapper used to convert the errors returned by
+// the resources, if nil the DefaultErrorMapper is used.
+func (h *RestHandler) SetErrorMapper(mapper ErrorMapper) {
+	h.errorMapper = mapper
+}
+
+// MapError converts the error into a status code and a Response using the
+// ErrorMapper of the RestHandler serving the request, it can be used by
+// middlewares and by resources not returning errors.
+func MapError(r *http.Request, err error) (int, Response) {
+	h := servingHandler(r)
+	if h == nil {
+		return DefaultErrorMapper.MapError(r, err)
+	}
+	return h.mapError(r, err)
+}
+
+// mapError converts the error using the configured ErrorMapper, errors
+// mapped to server errors are logged.
+func (h *RestHandler) mapError(r *http.Request, err error) (int, Response) {
+	mapper := h.errorMapper
+	if mapper == nil {
+		mapper = DefaultErrorMapper
+	}
+
+	code, response := mapper.MapError(r, err)
+	if code >= http.StatusInternalServerError {
+		h.logf("gorest: error serving %s %s: %s", r.Method, r.URL.Path, err.Error())
+	}
+	return code, response
+}
+
+// servingHandler returns the RestHandler serving the request, if any.
+func servingHandler(r *http.Request) *RestHandler {
+	h, _ := r.Context().Value(handlerContextKey{}).(*RestHandler)
+	return h
+}
+
+// withHandler returns a copy of the request carrying the serving RestHandler.
+func withHandler(r *http.Request, h *RestHandler) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), handlerContextKey{}, h))
+}
+
+// StatusCoder is the interface implemented by the responses returned by the
+// error-returning resource methods to set the status code of successful
+// requests, which otherwise is 200 OK.
+type StatusCoder interface {
+	GetStatusCode() int
+}
+
+// errorHandler adapts an error-returning Resource method to a Handler,
+// converting the errors using MapError.
+func errorHandler(fn func(*http.Request) (Response, error)) Handler {
+	return func(r *http.Request) (int, Response) {
+		response, err := fn(r)
+		if err != nil {
+			return MapError(r, err)
+		}
+		if coder, ok := response.(StatusCoder); ok && coder.GetStatusCode() != 0 {
+			return coder.GetStatusCode(), response
+		}
+		return http.StatusOK, response
+	}
+}
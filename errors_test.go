@@ -0,0 +1,140 @@
+package gorest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestDefaultErrorMapper verifies the default error mapping.
+func TestDefaultErrorMapper(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	tests := []struct {
+		err  error
+		code int
+		body string
+	}{
+		{ErrNotFound, http.StatusNotFound, `{"status":"NAK","message":"not found"}`},
+		{fmt.Errorf("post 12: %w", ErrNotFound), http.StatusNotFound, `{"status":"NAK","message":"not found"}`},
+		{ErrConflict, http.StatusConflict, `{"status":"NAK","message":"conflict"}`},
+		{ErrUnauthorized, http.StatusUnauthorized, `{"status":"NAK","message":"unauthorized"}`},
+		{Errorf(http.StatusTeapot, "short and %s", "stout"), http.StatusTeapot, `{"status":"NAK","message":"short and stout"}`},
+		{&ParamError{Name: "id", Value: "secret", Type: "int"}, http.StatusNotFound, `{"status":"NAK","message":"not found"}`},
+		{fmt.Errorf("query on 10.0.0.5 failed: %w", ErrConflict), http.StatusConflict, `{"status":"NAK","message":"conflict"}`},
+		{
+			fmt.Errorf("wrapped: %w", &ValidationError{Fields: []FieldError{{Pointer: "/name", Message: "is required"}}}),
+			http.StatusUnprocessableEntity,
+			`{"status":"NAK","message":"validation failed","errors":[{"pointer":"/name","message":"is required"}]}`,
+		},
+		{errors.New("database is on fire"), http.StatusInternalServerError, `{"status":"NAK","message":"Internal Server Error"}`},
+	}
+
+	for _, test := range tests {
+		code, response := DefaultErrorMapper.MapError(r, test.err)
+		if code != test.code {
+			t.Fatalf("Unexpected status code for %q. Expected: %d - Found: %d.", test.err.Error(), test.code, code)
+		}
+		body, err := response.GetBody()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s.", err.Error())
+		}
+		if string(body) != test.body {
+			t.Fatalf("Unexpected body for %q. Expected: %s - Found: %s.", test.err.Error(), test.body, string(body))
+		}
+	}
+}
+
+// TestValidationErrorMessage verifies the ValidationError message.
+func TestValidationErrorMessage(t *testing.T) {
+	err := &ValidationError{Fields: []FieldError{
+		{Pointer: "/name", Message: "is required"},
+		{Pointer: "/tags/0", Message: "is too long"},
+	}}
+	expected := "validation failed: /name: is required; /tags/0: is too long"
+	if err.Error() != expected {
+		t.Fatalf("Unexpected message. Expected: %s - Found: %s.", expected, err.Error())
+	}
+}
+
+// TestErrorResource verifies that the errors returned by the resources are
+// converted using the configured ErrorMapper.
+func TestErrorResource(t *testing.T) {
+	var buffer bytes.Buffer
+	h := New()
+	h.SetLogger(log.New(&buffer, "", 0))
+	h.RegisterRoute(NewRoute(testErrorResource{}, "/posts/{id}"))
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{http.MethodGet, "/posts/1", http.StatusOK, `{"status":"ACK"}`},
+		{http.MethodGet, "/posts/2", http.StatusNotFound, `{"status":"NAK","message":"not found"}`},
+		{http.MethodGet, "/posts/3", http.StatusInternalServerError, `{"status":"NAK","message":"Internal Server Error"}`},
+		{http.MethodPost, "/posts/1", http.StatusCreated, `created`},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+		if w.Code != test.code {
+			t.Fatalf("Unexpected status code for %s %s. Expected: %d - Found: %d.", test.method, test.path, test.code, w.Code)
+		}
+		if w.Body.String() != test.body {
+			t.Fatalf("Unexpected body for %s %s. Expected: %s - Found: %s.", test.method, test.path, test.body, w.Body.String())
+		}
+	}
+	if !strings.Contains(buffer.String(), "error serving GET /posts/3: boom") {
+		t.Fatalf("The server error should have been logged. Found: %s.", buffer.String())
+	}
+
+	// Custom error mappers replace the default one.
+	h.SetErrorMapper(ErrorMapperFunc(func(r *http.Request, err error) (int, Response) {
+		return http.StatusTeapot, nil
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/2", nil))
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusTeapot, w.Code)
+	}
+}
+
+// TestMapErrorOutsideHandler verifies that MapError falls back to the
+// DefaultErrorMapper when the request is not served by gorest.
+func TestMapErrorOutsideHandler(t *testing.T) {
+	code, _ := MapError(httptest.NewRequest(http.MethodGet, "/", nil), ErrConflict)
+	if code != http.StatusConflict {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusConflict, code)
+	}
+}
+
+// testErrorResource implements the error-returning methods.
+type testErrorResource struct{}
+
+func (testErrorResource) GetErr(r *http.Request) (Response, error) {
+	id, err := PathParamInt(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	switch id {
+	case 2:
+		return nil, fmt.Errorf("post %d: %w", id, ErrNotFound)
+	case 3:
+		return nil, errors.New("boom")
+	}
+	return NewSimpleResponse(ACK, ""), nil
+}
+
+func (testErrorResource) PostErr(r *http.Request) (Response, error) {
+	response := NewStandardResponse()
+	response.SetStatusCode(http.StatusCreated)
+	response.SetBody([]byte("created"))
+	return response, nil
+}
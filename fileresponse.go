@@ -0,0 +1,174 @@
+package gorest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileResponse is a response serving a file, or any blob backed by an
+// io.ReadSeeker, supporting range requests (single and multiple ranges) and
+// the conditional requests based on the modification time and, if set among
+// the headers, the ETag. The content is closed after being served if it
+// implements io.Closer.
+type FileResponse struct {
+	responseHeaders
+	responseCachePolicy
+	content     io.ReadSeeker
+	name        string
+	size        int64
+	modTime     time.Time
+	contentType string
+	disposition string
+}
+
+// NewFileResponse creates a new FileResponse serving the content with provided
+// name, used to detect the Content-Type, size and modification time. A
+// negative size means the size must be determined seeking the content, and
+// the zero time means the modification time is unknown.
+func NewFileResponse(content io.ReadSeeker, name string, size int64, modTime time.Time) *FileResponse {
+	return &FileResponse{content: content, name: name, size: size, modTime: modTime}
+}
+
+// OpenFileResponse creates a new FileResponse serving the file at provided
+// path.
+func OpenFileResponse(path string) (*FileResponse, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return NewFileResponse(file, filepath.Base(path), info.Size(), info.ModTime()), nil
+}
+
+// SetAttachment can be used to have the client download the content saving
+// it with provided filename.
+func (r *FileResponse) SetAttachment(filename string) {
+	r.disposition = contentDisposition("attachment", filename)
+}
+
+// SetInline can be used to have the client display the content, providing
+// the filename to use if it is saved.
+func (r *FileResponse) SetInline(filename string) {
+	r.disposition = contentDisposition("inline", filename)
+}
+
+// SetContentType can be used to declare the media type of the content, when
+// not declared it is detected from the name extension or the content.
+func (r *FileResponse) SetContentType(contentType string) {
+	r.contentType = contentType
+}
+
+// GetContentType will be used by gorest core to retrieve the Content-Type to
+// be sent in the HTTP response writer.
+func (r *FileResponse) GetContentType() string {
+	return r.contentType
+}
+
+// GetBody reads the whole content, it is used only when the response is not
+// served by gorest.
+func (r *FileResponse) GetBody() ([]byte, error) {
+	defer r.Close()
+	if _, err := r.content.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if r.size >= 0 {
+		return io.ReadAll(io.LimitReader(r.content, r.size))
+	}
+	return io.ReadAll(r.content)
+}
+
+// Close closes the content, if it implements io.Closer.
+func (r *FileResponse) Close() error {
+	if closer, ok := r.content.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// serveFile serves the content of the response, handling the range and
+// conditional requests when the status code is 200 OK.
+func (h *RestHandler) serveFile(w *responseWriter, r *http.Request, code int, response *FileResponse) {
+	defer response.Close()
+	setResponseHeaders(w, response)
+	if response.disposition != "" {
+		w.Header().Set("Content-Disposition", response.disposition)
+	}
+
+	content := response.content
+	if response.size >= 0 {
+		content = &sizedReadSeeker{ReadSeeker: content, size: response.size}
+	}
+	if code == http.StatusOK {
+		http.ServeContent(w, r, response.name, response.modTime, content)
+		return
+	}
+
+	w.WriteHeader(code)
+	if r.Method != http.MethodHead {
+		if _, err := io.Copy(w, content); err != nil {
+			h.streamError(r, err)
+		}
+	}
+}
+
+// sizedReadSeeker is an io.ReadSeeker whose size is known, seeking relative
+// to the end does not require the underlying one to find it.
+type sizedReadSeeker struct {
+	io.ReadSeeker
+	size int64
+}
+
+// Seek sets the offset for the next read.
+func (s *sizedReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd {
+		return s.ReadSeeker.Seek(s.size+offset, io.SeekStart)
+	}
+	return s.ReadSeeker.Seek(offset, whence)
+}
+
+// contentDisposition formats the Content-Disposition header according to
+// RFC 6266, non ASCII filenames are encoded with the RFC 8187 filename*
+// parameter along with an ASCII fallback.
+func contentDisposition(dispositionType, filename string) string {
+	var fallback strings.Builder
+	for _, c := range filename {
+		switch {
+		case c == '"' || c == '\\' || c < 0x20 || c > 0x7e:
+			fallback.WriteByte('_')
+		default:
+			fallback.WriteRune(c)
+		}
+	}
+
+	header := dispositionType + `; filename="` + fallback.String() + `"`
+	if fallback.String() != filename {
+		header += "; filename*=UTF-8''" + encodeExtValue(filename)
+	}
+	return header
+}
+
+// encodeExtValue percent-encodes the value according to the RFC 8187
+// attr-char production.
+func encodeExtValue(value string) string {
+	const hex = "0123456789ABCDEF"
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			encoded.WriteByte(c)
+			continue
+		}
+		encoded.WriteByte('%')
+		encoded.WriteByte(hex[c>>4])
+		encoded.WriteByte(hex[c&0x0f])
+	}
+	return encoded.String()
+}
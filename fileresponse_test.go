@@ -0,0 +1,202 @@
+package gorest
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFileContent is the content served by the file tests.
+const testFileContent = "0123456789abcdefghij"
+
+// testFileModTime is the modification time of the served content.
+var testFileModTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// serveTestFile serves a FileResponse configured by the function.
+func serveTestFile(req *http.Request, configure func(*FileResponse)) *httptest.ResponseRecorder {
+	h := New()
+	h.RegisterRoute(NewRoute(testStreamResource{func() Response {
+		response := NewFileResponse(strings.NewReader(testFileContent), "report.txt", int64(len(testFileContent)), testFileModTime)
+		if configure != nil {
+			configure(response)
+		}
+		return response
+	}}, "/"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// TestFileResponseFull verifies that the whole content is served with the
+// Last-Modified and Content-Disposition headers.
+func TestFileResponseFull(t *testing.T) {
+	w := serveTestFile(httptest.NewRequest(http.MethodGet, "/", nil), func(r *FileResponse) {
+		r.SetAttachment("report.txt")
+	})
+
+	res := w.Result()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, res.StatusCode)
+	}
+	if body := w.Body.String(); body != testFileContent {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", testFileContent, body)
+	}
+	if modified := res.Header.Get("Last-Modified"); modified != testFileModTime.Format(http.TimeFormat) {
+		t.Fatalf("Unexpected Last-Modified. Expected: %s - Found: %s.", testFileModTime.Format(http.TimeFormat), modified)
+	}
+	if contentType := res.Header.Get("Content-Type"); contentType != "text/plain; charset=utf-8" {
+		t.Fatalf("Unexpected Content-Type. Expected: text/plain; charset=utf-8 - Found: %s.", contentType)
+	}
+	if disposition := res.Header.Get("Content-Disposition"); disposition != `attachment; filename="report.txt"` {
+		t.Fatalf("Unexpected Content-Disposition. Expected: attachment; filename=\"report.txt\" - Found: %s.", disposition)
+	}
+	if ranges := res.Header.Get("Accept-Ranges"); ranges != "bytes" {
+		t.Fatalf("Unexpected Accept-Ranges. Expected: bytes - Found: %s.", ranges)
+	}
+}
+
+// TestFileResponseCookiesAndPolicy verifies that the files support the
+// cookies, headers and caching policy methods of the other responses.
+func TestFileResponseCookiesAndPolicy(t *testing.T) {
+	w := serveTestFile(httptest.NewRequest(http.MethodGet, "/", nil), func(r *FileResponse) {
+		r.AddCookie(&http.Cookie{Name: "a", Value: "1"})
+		r.DeleteCookie("b", "/")
+		r.AddHeader("X-Report", "1")
+		r.AddHeader("X-Report", "2")
+		r.SetCachePolicy(CachePolicy{Public: true, MaxAge: time.Minute})
+	})
+
+	res := w.Result()
+	if cookies := res.Cookies(); len(cookies) != 2 || cookies[0].Name != "a" || cookies[1].Name != "b" || cookies[1].MaxAge != -1 {
+		t.Fatalf("Unexpected cookies: %v.", cookies)
+	}
+	if values := res.Header["X-Report"]; len(values) != 2 {
+		t.Fatalf("Unexpected X-Report values. Expected: 2 - Found: %d.", len(values))
+	}
+	if value := res.Header.Get("Cache-Control"); value != "public, max-age=60" {
+		t.Fatalf("Unexpected Cache-Control. Expected: public, max-age=60 - Found: %s.", value)
+	}
+}
+
+// TestFileResponseRanges verifies the single and multiple range requests.
+func TestFileResponseRanges(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	w := serveTestFile(req, nil)
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusPartialContent, w.Code)
+	}
+	if body := w.Body.String(); body != "2345" {
+		t.Fatalf("Unexpected body. Expected: 2345 - Found: %s.", body)
+	}
+	if contentRange := w.Result().Header.Get("Content-Range"); contentRange != "bytes 2-5/20" {
+		t.Fatalf("Unexpected Content-Range. Expected: bytes 2-5/20 - Found: %s.", contentRange)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-1,-2")
+	w = serveTestFile(req, nil)
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusPartialContent, w.Code)
+	}
+	mediaType, params, _ := mime.ParseMediaType(w.Result().Header.Get("Content-Type"))
+	if mediaType != "multipart/byteranges" {
+		t.Fatalf("Unexpected Content-Type. Expected: multipart/byteranges - Found: %s.", mediaType)
+	}
+	var parts []string
+	reader := multipart.NewReader(w.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %s.", err.Error())
+		}
+		data, _ := io.ReadAll(part)
+		parts = append(parts, string(data))
+	}
+	if strings.Join(parts, ",") != "01,ij" {
+		t.Fatalf("Unexpected parts. Expected: 01,ij - Found: %v.", parts)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=30-40")
+	w = serveTestFile(req, nil)
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusRequestedRangeNotSatisfiable, w.Code)
+	}
+}
+
+// TestFileResponseConditional verifies the If-Range and If-Modified-Since
+// handling.
+func TestFileResponseConditional(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	req.Header.Set("If-Range", testFileModTime.Add(-time.Hour).Format(http.TimeFormat))
+	w := serveTestFile(req, nil)
+	if w.Code != http.StatusOK || w.Body.String() != testFileContent {
+		t.Fatalf("Unexpected response. Expected: full content - Found: %d %s.", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	req.Header.Set("If-Range", testFileModTime.Format(http.TimeFormat))
+	w = serveTestFile(req, nil)
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusPartialContent, w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-Modified-Since", testFileModTime.Format(http.TimeFormat))
+	w = serveTestFile(req, nil)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusNotModified, w.Code)
+	}
+}
+
+// TestContentDisposition verifies the RFC 6266 encoding of the filenames.
+func TestContentDisposition(t *testing.T) {
+	tests := map[string]string{
+		"report.pdf":      `attachment; filename="report.pdf"`,
+		`quoted "x".txt`:  `attachment; filename="quoted _x_.txt"; filename*=UTF-8''quoted%20%22x%22.txt`,
+		"résumé 2020.pdf": `attachment; filename="r_sum_ 2020.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9%202020.pdf`,
+	}
+	for filename, expected := range tests {
+		if disposition := contentDisposition("attachment", filename); disposition != expected {
+			t.Fatalf("Unexpected Content-Disposition. Expected: %s - Found: %s.", expected, disposition)
+		}
+	}
+}
+
+// TestOpenFileResponse verifies that files are served and closed.
+func TestOpenFileResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte(`{"a":1}`), 0600); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+
+	response, err := OpenFileResponse(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	body, err := response.GetBody()
+	if err != nil || string(body) != `{"a":1}` {
+		t.Fatalf("Unexpected body. Expected: {\"a\":1} - Found: %s (%v).", body, err)
+	}
+	if _, err := response.content.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("File should have been closed.")
+	}
+
+	if _, err := OpenFileResponse(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatalf("An error was expected. Found nil.")
+	}
+}
@@ -0,0 +1,185 @@
+package gorest
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+
+// decodeValues decodes form values and files into the struct pointed by v.
+// The fields are matched using the "form" tag, falling back to the "json" tag
+// and then to the field name; strings, booleans, numbers, slices and pointers
+// of them are supported, as well as *multipart.FileHeader and slices of them
+// for the files. The fields of the embedded structs are decoded as fields of
+// the parent struct.
+func decodeValues(values url.Values, files map[string][]*multipart.FileHeader, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("form values can only be decoded into a struct pointer")
+	}
+	return decodeStruct(values, files, rv.Elem())
+}
+
+// decodeStruct decodes form values and files into the fields of the
+// addressable struct value, including the ones promoted by the embedded
+// structs.
+func decodeStruct(values url.Values, files map[string][]*multipart.FileHeader, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if isEmbeddedStruct(field, "form") {
+			embedded := exportedField(rv, i)
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					embedded.Set(reflect.New(embedded.Type().Elem()))
+				}
+				embedded = embedded.Elem()
+			}
+			if err := decodeStruct(values, files, embedded); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name := fieldName(field, "form")
+		if name == "-" {
+			continue
+		}
+
+		if field.Type == fileHeaderType || field.Type == reflect.SliceOf(fileHeaderType) {
+			setFiles(rv.Field(i), files[name])
+			continue
+		}
+
+		fieldValues, ok := values[name]
+		if !ok || len(fieldValues) == 0 {
+			continue
+		}
+		if err := setValues(rv.Field(i), fieldValues); err != nil {
+			return &FieldError{Pointer: "/" + name, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// fieldName returns the name of the field according to provided tag, falling
+// back to the json tag and to the field name.
+func fieldName(field reflect.StructField, tag string) string {
+	for _, key := range []string{tag, "json"} {
+		if value := field.Tag.Get(key); value != "" {
+			if name := strings.Split(value, ",")[0]; name != "" {
+				return name
+			}
+		}
+	}
+	return field.Name
+}
+
+// isEmbeddedStruct verifies whether the fields of the embedded struct are
+// promoted to the parent one, as encoding/json does: the field is anonymous,
+// it is not named by the provided tag nor by the json one and its type is a
+// struct or a pointer to a struct, even if unexported.
+func isEmbeddedStruct(field reflect.StructField, tag string) bool {
+	if !field.Anonymous {
+		return false
+	}
+	for _, key := range []string{tag, "json"} {
+		if name := strings.Split(field.Tag.Get(key), ",")[0]; name != "" {
+			return false
+		}
+	}
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// exportedField returns the field of the addressable struct value, giving
+// access to the exported fields promoted by the unexported embedded structs,
+// which reflect would otherwise mark as read-only.
+func exportedField(value reflect.Value, i int) reflect.Value {
+	field := value.Field(i)
+	if value.Type().Field(i).PkgPath == "" {
+		return field
+	}
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
+
+// setFiles sets the uploaded files into the field.
+func setFiles(field reflect.Value, files []*multipart.FileHeader) {
+	if len(files) == 0 {
+		return
+	}
+	if field.Kind() == reflect.Slice {
+		field.Set(reflect.ValueOf(files))
+		return
+	}
+	field.Set(reflect.ValueOf(files[0]))
+}
+
+// setValues converts and sets the values into the field.
+func setValues(field reflect.Value, values []string) error {
+	switch field.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	case reflect.Ptr:
+		ptr := reflect.New(field.Type().Elem())
+		if err := setValue(ptr.Elem(), values[0]); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	return setValue(field, values[0])
+}
+
+// setValue converts and sets a single value into the field.
+func setValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		converted, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		field.SetBool(converted)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		converted, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		field.SetInt(converted)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		converted, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("must be an unsigned integer")
+		}
+		field.SetUint(converted)
+	case reflect.Float32, reflect.Float64:
+		converted, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		field.SetFloat(converted)
+	default:
+		return errors.New("unsupported field type " + field.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package gorest
+
+import (
+	"mime/multipart"
+	"net/url"
+	"testing"
+)
+
+// TestDecodeValues verifies the form values decoding.
+func TestDecodeValues(t *testing.T) {
+	type form struct {
+		Name     string `form:"name"`
+		Email    string `json:"email,omitempty"`
+		Plain    string
+		Active   bool                    `form:"active"`
+		Count    int8                    `form:"count"`
+		Size     uint                    `form:"size"`
+		Ratio    float64                 `form:"ratio"`
+		Tags     []string                `form:"tag"`
+		Optional *int                    `form:"optional"`
+		Skipped  string                  `form:"-"`
+		File     *multipart.FileHeader   `form:"file"`
+		Files    []*multipart.FileHeader `form:"files"`
+		hidden   string
+	}
+
+	values := url.Values{
+		"name":     {"fred"},
+		"email":    {"fred@example.com"},
+		"Plain":    {"plain"},
+		"active":   {"true"},
+		"count":    {"-3"},
+		"size":     {"7"},
+		"ratio":    {"0.5"},
+		"tag":      {"a", "b"},
+		"optional": {"42"},
+		"-":        {"skipped"},
+		"hidden":   {"hidden"},
+	}
+	files := map[string][]*multipart.FileHeader{
+		"file":  {{Filename: "a.txt"}},
+		"files": {{Filename: "b.txt"}, {Filename: "c.txt"}},
+	}
+
+	var f form
+	if err := decodeValues(values, files, &f); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	if f.Name != "fred" || f.Email != "fred@example.com" || f.Plain != "plain" {
+		t.Fatalf("Unexpected strings: %+v.", f)
+	}
+	if !f.Active || f.Count != -3 || f.Size != 7 || f.Ratio != 0.5 {
+		t.Fatalf("Unexpected scalars: %+v.", f)
+	}
+	if len(f.Tags) != 2 || f.Tags[0] != "a" || f.Tags[1] != "b" {
+		t.Fatalf("Unexpected tags: %v.", f.Tags)
+	}
+	if f.Optional == nil || *f.Optional != 42 {
+		t.Fatalf("Unexpected optional: %v.", f.Optional)
+	}
+	if f.Skipped != "" || f.hidden != "" {
+		t.Fatalf("Unexpected skipped fields: %+v.", f)
+	}
+	if f.File == nil || f.File.Filename != "a.txt" || len(f.Files) != 2 {
+		t.Fatalf("Unexpected files: %+v - %+v.", f.File, f.Files)
+	}
+}
+
+// TestDecodeValuesEmbedded verifies that the fields of the embedded structs,
+// either exported or not, are decoded as fields of the parent struct.
+func TestDecodeValuesEmbedded(t *testing.T) {
+	type form struct {
+		testBase
+		*TestExportedBase
+	}
+
+	var f form
+	if err := decodeValues(url.Values{"id": {"1"}, "name": {"fred"}}, nil, &f); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	if f.ID != "1" || f.TestExportedBase == nil || f.Name != "fred" {
+		t.Fatalf("Unexpected embedded fields: %+v - %+v.", f.testBase, f.TestExportedBase)
+	}
+}
+
+// TestDecodeValuesErrors verifies the form values decoding errors.
+func TestDecodeValuesErrors(t *testing.T) {
+	type form struct {
+		Count  int8           `form:"count"`
+		Active bool           `form:"active"`
+		Size   uint           `form:"size"`
+		Ratio  float32        `form:"ratio"`
+		Map    map[string]int `form:"map"`
+	}
+
+	tests := map[string]string{
+		"count":  "/count: must be an integer",
+		"active": "/active: must be a boolean",
+		"size":   "/size: must be an unsigned integer",
+		"ratio":  "/ratio: must be a number",
+		"map":    "/map: unsupported field type map[string]int",
+	}
+	for name, expected := range tests {
+		var f form
+		err := decodeValues(url.Values{name: {"invalid"}}, nil, &f)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Unexpected error. Expected: %s - Found: %v.", expected, err)
+		}
+	}
+
+	if err := decodeValues(url.Values{"count": {"1000"}}, nil, &form{}); err == nil {
+		t.Fatalf("An error was expected for overflowing values. Found nil.")
+	}
+	if err := decodeValues(url.Values{}, nil, form{}); err == nil {
+		t.Fatalf("An error was expected for non pointer values. Found nil.")
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/fredmaggiowski/gorest
 
+go 1.27.1
+
 require github.com/gorilla/mux v1.7.0
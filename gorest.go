@@ -1,17 +1,46 @@
 package gorest
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 )
 
 // RestHandler defines a utility structure that provides REST handling functions.
+// It implements the http.Handler interface and can be used directly in an
+// HTTP server, or converted to a Gorilla Mux router using GetMuxRouter.
 type RestHandler struct {
-	routes []*Route // List of all the available routes.
+	routes     []*Route       // List of all the available routes.
+	middleware []Middleware   // Middlewares wrapping every route.
+	mounts     []mount        // Sub-handlers mounted under a prefix.
+	parents    []*RestHandler // Handlers this one is mounted into.
+	logger     *log.Logger    // Logger used to report anomalies.
+
+	errorMapper    ErrorMapper // Converts the errors returned by the resources.
+	problemDetails *bool       // Whether internal errors use ProblemResponse, nil if not set.
+
+	panicHandler    PanicHandler // Invoked when a panic is recovered.
+	developmentMode *bool        // Whether recovered panics are propagated, nil if not set.
+
+	decoders   map[string]Decoder       // Request body decoders by media type.
+	validators map[string]ValidatorFunc // Registered validation rules.
+	validator  *Validator               // Validator of the decoded payloads.
+	encoders   []encoderEntry           // Registered response value encoders by preference.
+
+	streamErrorHandler  StreamErrorHandler // Invoked when streaming fails.
+	compressionMinSize  int                // Minimum size of the compressed bodies, 0 if disabled.
+	maxDecompressedSize int64              // Maximum size of the decompressed request bodies.
+
+	mu     sync.Mutex // Guards the native router build.
+	router *router    // Native router, built lazily from the routes.
 }
 
 // NewHandler creates a new Handler instance.
@@ -25,133 +54,305 @@ func New() *RestHandler {
 	return &RestHandler{}
 }
 
-// GetRoutes defines and returns all the handled Resource routes.
+// GetRoutes defines and returns all the handled Resource routes, including
+// the ones of the mounted groups with their fully-resolved patterns.
 func (h *RestHandler) GetRoutes() []*Route {
-	return h.routes
+	if len(h.mounts) == 0 {
+		return h.routes
+	}
+	return append(append([]*Route{}, h.routes...), h.resolveRoutes()...)
 }
 
 // RegisterRoute register provided route into the internal routes list.
+// It panics if the pattern has a parameter constraint which is not a valid
+// regular expression.
 func (h *RestHandler) RegisterRoute(route *Route) {
+	mustValidatePattern(route.GetPattern())
+	route.handlers = append(route.handlers, h)
 	h.routes = append(h.routes, route)
+	h.resetRouter()
 }
 
 // SetRoutes returns all the handled Resource routes.
+// It panics if a pattern has a parameter constraint which is not a valid
+// regular expression.
 func (h *RestHandler) SetRoutes(routes []*Route) {
+	for _, route := range routes {
+		mustValidatePattern(route.GetPattern())
+		route.handlers = append(route.handlers, h)
+	}
 	h.routes = routes
+	h.resetRouter()
+}
+
+// SetLogger sets the logger used to report anomalies occurred while serving
+// the requests, if nil the standard logger is used.
+func (h *RestHandler) SetLogger(logger *log.Logger) {
+	h.logger = logger
+}
+
+// logf prints a message using the configured logger.
+func (h *RestHandler) logf(format string, args ...interface{}) {
+	if h.logger != nil {
+		h.logger.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// resetRouter discards the native router, and the ones of the handlers it is
+// mounted into, so that they are built again on the next request.
+func (h *RestHandler) resetRouter() {
+	h.mu.Lock()
+	h.router = nil
+	h.mu.Unlock()
+
+	for _, parent := range h.parents {
+		parent.resetRouter()
+	}
 }
 
 // handleRoute returns the handler function for a specific handler
 func (h *RestHandler) handleRoute(route *Route) http.HandlerFunc {
-	return func(w http.ResponseWriter, request *http.Request) {
-		// Try to parse the request form data.
-		if request.ParseForm() != nil {
-			w.WriteHeader(http.StatusBadRequest)
+	middleware := append(append([]Middleware{}, h.middleware...), route.GetMiddleware()...)
+	if route.owner != nil {
+		// Routes of the mounted sub-handlers are served with their settings.
+		h = route.owner
+	}
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		w := &responseWriter{ResponseWriter: writer}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				h.recoverPanic(w, request, route, recovered)
+			}
+		}()
+
+		// Try to parse the request form data, within the size limits of the
+		// route, once the body has been decompressed.
+		limitBody(request, route)
+		var gorestErr *Error
+		if err := h.decompressBody(request); errors.As(err, &gorestErr) {
+			if gorestErr.Code == http.StatusUnsupportedMediaType {
+				w.Header().Set("Accept-Encoding", "gzip, deflate")
+			}
+			h.writeError(w, request, gorestErr.Code, gorestErr.Message)
+			return
+		}
+		if err := request.ParseForm(); err != nil {
+			if errors.Is(err, errLimitExceeded) {
+				h.writeError(w, request, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			h.writeError(w, request, http.StatusBadRequest, "invalid form data")
 			return
 		}
 
 		// Get handler function for specified resource for the route.
 		handler := h.getHandlerFunction(request.Method, route.GetResource())
+		if res, ok := route.GetResource().(WebSocketSupported); ok && isWebSocketUpgrade(request) {
+			handler = webSocketHandler(res)
+		}
+		if handler == nil && request.Method == http.MethodOptions {
+			handler = optionsHandler(route)
+		}
 		if handler == nil {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Header().Set("Allow", strings.Join(route.GetMethods(), ", "))
+			h.writeError(w, request, http.StatusMethodNotAllowed, "method "+request.Method+" is not supported")
 			return
 		}
 
+		// Make the handler and the route available to middlewares and wrap
+		// the resource handler.
+		method := handlerMethod(request.Method, route.GetResource())
+		request = withHandler(request, h)
+		request = withRouteInfo(request, RouteInfo{
+			Route:   route,
+			Pattern: route.GetPattern(),
+			Method:  method,
+		})
+		request, removeForms := trackMultipartForms(request)
+		defer removeForms()
+		handler = h.payloadHandler(handler, route.GetResource(), method)
+		var version Version
+		handler = h.preconditionHandler(handler, route, method, &version)
+		handler = chain(handler, middleware)
+
 		// Invoke the proper handler and retrieve the response and status code.
 		code, response := handler(request)
+		setVersionHeaders(w.Header(), version)
+
+		// The request context is cancelled when the client disconnects.
+		if request.Context().Err() == context.Canceled {
+			h.logf("gorest: client disconnected while serving %s %s", request.Method, request.URL.Path)
+		}
 
 		// TODO: consider logging a warning for invalid requests (40X - 50X)
 		if code != http.StatusOK && code != http.StatusPermanentRedirect && code != http.StatusTemporaryRedirect {
 		}
 
+		// WebSocket upgrades which have not been rejected by the middlewares.
+		if upgrade, ok := response.(*webSocketResponse); ok {
+			h.upgradeWebSocket(w, upgrade)
+			return
+		}
+
+		// Files support range and conditional requests, like streamed bodies
+		// they follow the caching policy, overridden by the response headers.
+		if file, ok := response.(*FileResponse); ok {
+			setCachePolicy(w.Header(), request, code, route, response)
+			h.serveFile(w, request, code, file)
+			return
+		}
+
+		// Streamed bodies and events are written as they are produced.
+		if events, ok := response.(*EventResponse); ok {
+			h.writeEvents(w, request, code, events)
+			return
+		}
+		if streamer, ok := response.(Streamer); ok {
+			setCachePolicy(w.Header(), request, code, route, response)
+			h.writeStream(w, request, code, response, streamer)
+			return
+		}
+
 		var responseBody []byte
 		var err error
 		if response != nil {
-			// Retrieve the body to be transmitted
-			responseBody, err = response.GetBody()
+			// Retrieve the body to be transmitted, encoding the value of the
+			// negotiable responses in the media type accepted by the client.
+			if negotiable, ok := response.(Negotiable); ok {
+				var contentType string
+				addVary(w.Header(), "Accept")
+				responseBody, contentType, err = h.encode(request, negotiable.GetValue())
+				var gorestErr *Error
+				if errors.As(err, &gorestErr) {
+					h.writeError(w, request, gorestErr.Code, gorestErr.Message)
+					return
+				}
+				w.Header().Set("Content-Type", contentType)
+			} else {
+				responseBody, err = response.GetBody()
+			}
 			if err != nil {
-				// TODO: Consider logging or returning the error.
-				w.WriteHeader(http.StatusInternalServerError)
+				h.logf("gorest: failed retrieving response body for %s %s: %s", request.Method, request.URL.Path, err.Error())
+				h.writeError(w, request, http.StatusInternalServerError, "failed preparing the response")
 				return
 			}
 
-			// cache successful GET request via ETAG
-			// with forced revalidation on each request.
-			if request.Method == http.MethodGet && code == http.StatusOK {
-				// Generate new ETAG, force cache revalidation and set the etag.
-				etag := getETag(responseBody)
-				w.Header().Set("Cache-Control", "private, max-age=0, must-revalidate")
-				w.Header().Set("ETag", etag)
-
-				// Check if request has an etag set and compare, return status code
-				// 304 NOT MODIFIED if they match.
-				if requestEtag := request.Header.Get("If-None-Match"); requestEtag == etag {
-					w.WriteHeader(http.StatusNotModified)
-					return
-				}
+			setResponseHeaders(w, response)
+		}
+		if w.Header().Get("Content-Type") == "" && len(responseBody) > 0 {
+			w.Header().Set("Content-Type", detectContentType(responseBody))
+		}
+
+		// Compress the body if accepted by the client, the ETag is specific
+		// to the encoding.
+		uncompressedBody, etagSuffix := responseBody, ""
+		if encoding := h.negotiateEncoding(w, request, route, responseBody); encoding != "" {
+			compressed, err := compress(encoding, responseBody)
+			if err != nil {
+				h.logf("gorest: failed compressing response body for %s %s: %s", request.Method, request.URL.Path, err.Error())
+				h.writeError(w, request, http.StatusInternalServerError, "failed preparing the response")
+				return
 			}
+			w.Header().Set("Content-Encoding", encoding)
+			etagSuffix = "-" + encoding
+			setEncodingETag(w.Header(), encoding)
+			responseBody = compressed
+		} else if code == http.StatusNotModified {
+			restoreEncodingETag(w.Header(), request)
+		}
 
-			// Verify if a cookie is needed and set it.
-			if cookie := response.GetCookie(); cookie != nil {
-				http.SetCookie(w, cookie)
+		// cache successful GET (and HEAD) request via ETAG, according to
+		// the caching policy of the route or the response.
+		setCachePolicy(w.Header(), request, code, route, response)
+		if response != nil && isGetOrHead(request.Method) && code == http.StatusOK {
+			// Generate new ETAG, unless set by the response.
+			if w.Header().Get("ETag") == "" {
+				w.Header().Set("ETag", quoteETag(getETag(uncompressedBody)+etagSuffix))
 			}
-			// Verfiy if a set of headers is needed by the response
-			// and if so set them all.
-			if headers := response.GetHeaders(); headers != nil {
-				for key, val := range headers {
-					w.Header().Set(key, strings.Join(val, ", "))
-				}
+
+			// Check if request has an etag set and compare, return status code
+			// 304 NOT MODIFIED if they match.
+			if notModified(request, w.Header()) {
+				w.WriteHeader(http.StatusNotModified)
+				return
 			}
 		}
-		// Write status code and data.
+
+		// HEAD responses carry the length of the body they would have sent,
+		// which is then discarded; 304 responses have no body to describe.
+		if request.Method == http.MethodHead && code != http.StatusNotModified {
+			w.Header().Set("Content-Length", strconv.Itoa(len(responseBody)))
+			responseBody = nil
+		}
+
+		// Write status code and data, all the headers must be set before.
 		w.WriteHeader(code)
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 		w.Write(responseBody)
 	}
 }
 
+// setResponseHeaders sets the headers and the cookies of the response.
+func setResponseHeaders(w http.ResponseWriter, response Response) {
+	// Verify if a set of headers is needed by the response and if so set them
+	// all, replacing the ones set by gorest, apart from Vary, and sending each
+	// value separately.
+	for key, values := range response.GetHeaders() {
+		if key = http.CanonicalHeaderKey(key); key == "Vary" {
+			addVary(w.Header(), values...)
+			continue
+		}
+		w.Header()[key] = append([]string(nil), values...)
+	}
+	// Verify if cookies are needed and set them.
+	for _, cookie := range responseCookies(response) {
+		http.SetCookie(w, cookie)
+	}
+	// The Content-Type declared by the response takes precedence, it is
+	// detected from the body when not set at all.
+	if typer, ok := response.(ContentTyper); ok && typer.GetContentType() != "" {
+		w.Header().Set("Content-Type", typer.GetContentType())
+	}
+}
+
 // getHandlerFunction returns the actual http handler implementation based on
 // resource type and request method.
 func (h *RestHandler) getHandlerFunction(requestMethod string, r Resource) Handler {
 	// TODO: Consider logging.
-	var handler Handler
-	switch requestMethod {
-	case http.MethodGet:
-		if res, ok := r.(GetSupported); ok {
-			handler = res.Get
-		}
-	case http.MethodPost:
-		if res, ok := r.(PostSupported); ok {
-			handler = res.Post
-		}
-	case http.MethodPut:
-		if res, ok := r.(PutSupported); ok {
-			handler = res.Put
-		}
-	case http.MethodDelete:
-		if res, ok := r.(DeleteSupported); ok {
-			handler = res.Delete
-		}
-	case http.MethodHead:
-		if res, ok := r.(HeadSupported); ok {
-			handler = res.Head
-		}
-	case http.MethodPatch:
-		if res, ok := r.(PatchSupported); ok {
-			handler = res.Patch
-		}
-	}
+	// HEAD requests are served by the Get method when Head is not supported,
+	// the body is then discarded by handleRoute.
+	return resourceHandler(handlerMethod(requestMethod, r), r)
+}
+
+// isGetOrHead verifies whether the method is GET or HEAD.
+func isGetOrHead(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
 
-	return handler
+// optionsHandler returns the handler automatically replying to OPTIONS
+// requests with the methods supported by the route.
+func optionsHandler(route *Route) Handler {
+	return func(*http.Request) (int, Response) {
+		response := NewStandardResponse()
+		response.SetHeaders(http.Header{"Allow": {strings.Join(route.GetMethods(), ", ")}})
+		return http.StatusNoContent, response
+	}
 }
 
 // GetMuxRouter returns a Gorilla Mux router which implements all
 // defined Routes.
+// The RestHandler can be served directly since it implements http.Handler,
+// this adapter is kept for applications already relying on Gorilla Mux.
+// The routes are added to the router as they are configured when it is
+// invoked, the routes and middlewares changed afterwards are not applied.
 func (h *RestHandler) GetMuxRouter(router *mux.Router) *mux.Router {
 	if router == nil {
 		router = mux.NewRouter().StrictSlash(true)
 	}
 	for _, route := range h.GetRoutes() {
-		router.HandleFunc(route.GetPattern(), h.handleRoute(route))
+		router.HandleFunc(expandPattern(route.GetPattern()), h.handleRoute(route))
 	}
 	return router
 }
@@ -2,6 +2,8 @@ package gorest
 
 import (
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -12,6 +14,9 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// discardLogger is used to silence the logs of the handlers under test.
+var discardLogger = log.New(io.Discard, "", 0)
+
 // TestRegisterRoute verifies that registering a single route works.
 func TestRegisterRoute(t *testing.T) {
 	h := NewHandler()
@@ -229,6 +234,25 @@ func TestGetHandlerFunction(t *testing.T) {
 	if fn := GetFunctionName(handler); !strings.ContainsAny(fn, "Patch") {
 		t.Fatalf("Unexpected function name. Expected: %s - Found: %s.", "Patch", fn)
 	}
+	handler = h.getHandlerFunction(http.MethodHead, testResourceWithGet{})
+	if handler == nil {
+		t.Fatalf("Unexpected nil handler.")
+	}
+	if fn := GetFunctionName(handler); !strings.Contains(fn, "Get") {
+		t.Fatalf("Unexpected function name. Expected: %s - Found: %s.", "Get", fn)
+	}
+	// OPTIONS
+	handler = h.getHandlerFunction(http.MethodOptions, testResourceWithOptions{})
+	if handler == nil {
+		t.Fatalf("Unexpected nil handler.")
+	}
+	if fn := GetFunctionName(handler); !strings.ContainsAny(fn, "Options") {
+		t.Fatalf("Unexpected function name. Expected: %s - Found: %s.", "Options", fn)
+	}
+	handler = h.getHandlerFunction(http.MethodOptions, testResourceWithGet{})
+	if handler != nil {
+		t.Fatalf("Unexpected non-nil handler: %+v.", handler)
+	}
 }
 
 // TestGetMuxRouter verifies that a filled-in mux router is returned.
@@ -284,6 +308,48 @@ func TestHandleRouteFailsForUnsupportedMethod(t *testing.T) {
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Fatalf("Unexpected status code. Expected: %d - Found. %d.", http.StatusMethodNotAllowed, w.Code)
 	}
+	if allow := w.Result().Header.Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Fatalf("Unexpected Allow header. Expected: %s - Found: %s.", "GET, HEAD, OPTIONS", allow)
+	}
+}
+
+// TestHandleRouteAutomaticOptions verifies that OPTIONS requests are answered
+// automatically with the supported methods.
+func TestHandleRouteAutomaticOptions(t *testing.T) {
+	h := NewHandler()
+	route := NewRoute(testResourceWithPost{}, "/")
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	w := httptest.NewRecorder()
+
+	handler := h.handleRoute(route)
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Unexpected status code. Expected: %d - Found. %d.", http.StatusNoContent, w.Code)
+	}
+	if allow := w.Result().Header.Get("Allow"); allow != "POST, OPTIONS" {
+		t.Fatalf("Unexpected Allow header. Expected: %s - Found: %s.", "POST, OPTIONS", allow)
+	}
+	if w.Body.String() != "" {
+		t.Fatalf("Unexpected body. Expected: '' - Found: %s.", w.Body.String())
+	}
+}
+
+// TestHandleRouteCustomOptions verifies that resources implementing the
+// Options method handle OPTIONS requests on their own.
+func TestHandleRouteCustomOptions(t *testing.T) {
+	h := NewHandler()
+	route := NewRoute(testResourceWithOptions{}, "/")
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	w := httptest.NewRecorder()
+
+	handler := h.handleRoute(route)
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found. %d.", http.StatusOK, w.Code)
+	}
+	if allow := w.Result().Header.Get("Allow"); allow != "" {
+		t.Fatalf("Unexpected Allow header. Expected: '' - Found: %s.", allow)
+	}
 }
 
 // TestHandleRouteVerifyFlowWithNilResponse the HandleRoute function has two
@@ -310,6 +376,7 @@ func TestHandleRouteVerifyFlowWithNilResponse(t *testing.T) {
 // the processing flow when handle Response is not nil but has returned an error.
 func TestHandleRouteOKWithResponseError(t *testing.T) {
 	h := NewHandler()
+	h.SetLogger(discardLogger)
 	route := NewRoute(testResourceWithGetAndResponse{
 		testResponse{
 			body:    "",
@@ -367,7 +434,7 @@ func TestHandlerRouteOKWithCachableResponse(t *testing.T) {
 		},
 	}, "/")
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("If-None-Match", getETag([]byte("testbody")))
+	req.Header.Set("If-None-Match", quoteETag(getETag([]byte("testbody"))))
 	w := httptest.NewRecorder()
 
 	handler := h.handleRoute(route)
@@ -453,3 +520,173 @@ func TestHandleRouteOKWithResponseAndHeaders(t *testing.T) {
 		t.Fatalf("Unexpected Test-Header value. Expected: %s - Found: %s.", "my-value", headers["Test-Header"][0])
 	}
 }
+
+// TestHandleRouteHeadDerivedFromGet verifies that HEAD requests are served by
+// the resource Get method, with the body discarded.
+func TestHandleRouteHeadDerivedFromGet(t *testing.T) {
+	h := NewHandler()
+	route := NewRoute(testResourceWithGetAndResponse{
+		testResponse{body: "testbody"},
+	}, "/")
+	req := httptest.NewRequest(http.MethodHead, "/", nil)
+	w := httptest.NewRecorder()
+
+	handler := h.handleRoute(route)
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found. %d.", http.StatusOK, w.Code)
+	}
+	if w.Body.String() != "" {
+		t.Fatalf("Unexpected body. Expected: '' - Found: %s.", w.Body.String())
+	}
+	headers := w.Result().Header
+	if length := headers.Get("Content-Length"); length != "8" {
+		t.Fatalf("Unexpected Content-Length. Expected: %s - Found: %s.", "8", length)
+	}
+	if etag := headers.Get("ETag"); etag != quoteETag(getETag([]byte("testbody"))) {
+		t.Fatalf("Unexpected ETag. Expected: %s - Found: %s.", quoteETag(getETag([]byte("testbody"))), etag)
+	}
+
+	// Conditional HEAD requests are handled as GET ones.
+	req = httptest.NewRequest(http.MethodHead, "/", nil)
+	req.Header.Set("If-None-Match", quoteETag(getETag([]byte("testbody"))))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("Unexpected status code. Expected: %d - Found. %d.", http.StatusNotModified, w.Code)
+	}
+}
+
+// TestHandleRouteHeadSupported verifies that resources implementing Head
+// serve HEAD requests on their own.
+func TestHandleRouteHeadSupported(t *testing.T) {
+	type resource struct {
+		testResourceWithGetAndResponse
+		testResourceWithHead
+	}
+
+	var method string
+	h := NewHandler()
+	h.Use(func(next Handler) Handler {
+		return func(r *http.Request) (int, Response) {
+			info, _ := GetRouteInfo(r)
+			method = info.Method
+			return next(r)
+		}
+	})
+
+	route := NewRoute(resource{}, "/")
+	w := httptest.NewRecorder()
+	h.handleRoute(route).ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found. %d.", http.StatusOK, w.Code)
+	}
+	if method != http.MethodHead {
+		t.Fatalf("Unexpected handler method. Expected: %s - Found: %s.", http.MethodHead, method)
+	}
+	if length := w.Result().Header.Get("Content-Length"); length != "0" {
+		t.Fatalf("Unexpected Content-Length. Expected: %s - Found: %s.", "0", length)
+	}
+
+	route = NewRoute(testResourceWithGet{}, "/")
+	h.handleRoute(route).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodHead, "/", nil))
+	if method != http.MethodGet {
+		t.Fatalf("Unexpected handler method. Expected: %s - Found: %s.", http.MethodGet, method)
+	}
+}
+
+// TestHandleRouteContentType verifies that the Content-Type is sent on the
+// wire, being set before the status line, according to the response.
+func TestHandleRouteContentType(t *testing.T) {
+	pngHeader := "\x89PNG\r\n\x1a\n"
+
+	tests := []struct {
+		name        string
+		response    func() Response
+		contentType string
+	}{
+		{"json", func() Response {
+			response := NewStandardResponse()
+			response.SetJSONBody(map[string]string{"a": "b"})
+			return response
+		}, "application/json; charset=UTF-8"},
+		{"declared", func() Response {
+			response := NewStandardResponse()
+			response.SetBody([]byte("<p>hello</p>"))
+			response.SetContentType("text/html", "utf-8")
+			return response
+		}, "text/html; charset=utf-8"},
+		{"header", func() Response {
+			response := NewStandardResponse()
+			response.SetBody([]byte("a,b"))
+			response.SetHeaders(http.Header{"Content-Type": {"text/csv"}})
+			return response
+		}, "text/csv"},
+		{"sniffed html", func() Response {
+			response := NewStandardResponse()
+			response.SetBody([]byte("<html><body>hello</body></html>"))
+			return response
+		}, "text/html; charset=utf-8"},
+		{"sniffed binary", func() Response {
+			response := NewStandardResponse()
+			response.SetBody([]byte(pngHeader))
+			return response
+		}, "image/png"},
+		{"empty", func() Response {
+			return NewStandardResponse()
+		}, ""},
+	}
+
+	for _, test := range tests {
+		response := test.response
+		h := New()
+		h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+			return http.StatusOK, response()
+		}), "/"))
+
+		// A real server is used since the headers set after the status line
+		// are dropped by net/http.
+		server := httptest.NewServer(h)
+		res, err := http.Get(server.URL)
+		server.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s.", err.Error())
+		}
+		res.Body.Close()
+
+		if contentType := res.Header.Get("Content-Type"); contentType != test.contentType {
+			t.Fatalf("Unexpected Content-Type for %s. Expected: %s - Found: %s.", test.name, test.contentType, contentType)
+		}
+	}
+}
+
+// TestHandleRouteMultiValueHeadersAndCookies verifies that each header value
+// and cookie is sent separately.
+func TestHandleRouteMultiValueHeadersAndCookies(t *testing.T) {
+	h := New()
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		response := NewStandardResponse()
+		response.AddHeader("Link", "</a>; rel=next")
+		response.AddHeader("Link", "</b>; rel=prev")
+		response.SetCookie(&http.Cookie{Name: "a", Value: "1"})
+		response.AddCookie(&http.Cookie{Name: "b", Value: "2"})
+		response.DeleteCookie("c", "/")
+		return http.StatusOK, response
+	}), "/"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	res := w.Result()
+	if links := res.Header["Link"]; len(links) != 2 {
+		t.Fatalf("Unexpected Link values. Expected: 2 values - Found: %v.", links)
+	}
+
+	cookies := res.Cookies()
+	if len(cookies) != 3 {
+		t.Fatalf("Unexpected cookies count. Expected: %d - Found: %d.", 3, len(cookies))
+	}
+	if cookies[2].Name != "c" || cookies[2].MaxAge >= 0 {
+		t.Fatalf("Unexpected deleted cookie. Expected: expired c - Found: %+v.", cookies[2])
+	}
+}
@@ -0,0 +1,131 @@
+package gorest
+
+import "strings"
+
+// mount is a sub-handler whose routes are served under a prefix.
+type mount struct {
+	prefix  string
+	handler *RestHandler
+}
+
+// Group creates a sub-handler whose routes are served under provided prefix
+// and wrapped by provided middlewares, after the ones of the parent handler.
+// Groups can be nested by creating groups of groups.
+func (h *RestHandler) Group(prefix string, middleware ...Middleware) *RestHandler {
+	group := New()
+	group.Use(middleware...)
+	h.Mount(prefix, group)
+	return group
+}
+
+// Mount serves all the routes of the sub-handler, including its own groups,
+// under provided prefix. The middlewares of the sub-handler wrap its routes
+// after the ones of the handler it is mounted into.
+// The routes are served using the settings of the sub-handler, like the
+// ErrorMapper or the registered decoders, falling back to the ones of the
+// handler it is mounted into for the settings it does not configure. The
+// settings are read when the routes are first served, so they must be
+// configured before serving the requests.
+// It panics if the prefix has a parameter constraint which is not a valid
+// regular expression.
+func (h *RestHandler) Mount(prefix string, sub *RestHandler) {
+	mustValidatePattern(prefix)
+	h.mounts = append(h.mounts, mount{prefix: prefix, handler: sub})
+	sub.parents = append(sub.parents, h)
+	h.resetRouter()
+}
+
+// resolveRoutes returns the routes of the mounted sub-handlers with their
+// patterns, middlewares and settings resolved against the mount prefix and
+// the handler.
+func (h *RestHandler) resolveRoutes() []*Route {
+	var routes []*Route
+	for _, m := range h.mounts {
+		for _, route := range m.handler.GetRoutes() {
+			owner := route.owner
+			if owner == nil {
+				owner = m.handler
+			}
+			resolved := route.resolve(m.prefix, m.handler.middleware)
+			resolved.owner = inherit(owner, h)
+			routes = append(routes, resolved)
+		}
+	}
+	return routes
+}
+
+// resolve returns a copy of the route with the pattern prefixed and the
+// middlewares preceded by the provided ones.
+func (r *Route) resolve(prefix string, middleware []Middleware) *Route {
+	resolved := *r
+	resolved.pattern = joinPattern(prefix, r.pattern)
+	resolved.middleware = append(append([]Middleware{}, middleware...), r.middleware...)
+	return &resolved
+}
+
+// inherit returns a handler with the settings of the sub-handler, falling
+// back to the ones of the parent for the settings it does not configure. The
+// registered decoders, encoders and validation rules are merged, preferring
+// the ones of the sub-handler.
+func inherit(sub, parent *RestHandler) *RestHandler {
+	h := &RestHandler{
+		logger:              sub.logger,
+		errorMapper:         sub.errorMapper,
+		problemDetails:      sub.problemDetails,
+		panicHandler:        sub.panicHandler,
+		developmentMode:     sub.developmentMode,
+		encoders:            mergeEncoders(sub.encoders, parent.encoders),
+		streamErrorHandler:  sub.streamErrorHandler,
+		compressionMinSize:  sub.compressionMinSize,
+		maxDecompressedSize: sub.maxDecompressedSize,
+	}
+	if h.logger == nil {
+		h.logger = parent.logger
+	}
+	if h.errorMapper == nil {
+		h.errorMapper = parent.errorMapper
+	}
+	if h.problemDetails == nil {
+		h.problemDetails = parent.problemDetails
+	}
+	if h.panicHandler == nil {
+		h.panicHandler = parent.panicHandler
+	}
+	if h.developmentMode == nil {
+		h.developmentMode = parent.developmentMode
+	}
+	if h.streamErrorHandler == nil {
+		h.streamErrorHandler = parent.streamErrorHandler
+	}
+	if h.compressionMinSize == 0 {
+		h.compressionMinSize = parent.compressionMinSize
+	}
+	if h.maxDecompressedSize == 0 {
+		h.maxDecompressedSize = parent.maxDecompressedSize
+	}
+	for _, decoders := range []map[string]Decoder{parent.decoders, sub.decoders} {
+		for mediaType, decoder := range decoders {
+			h.RegisterDecoder(mediaType, decoder)
+		}
+	}
+	for _, validators := range []map[string]ValidatorFunc{parent.validators, sub.validators} {
+		for name, fn := range validators {
+			h.RegisterValidator(name, fn)
+		}
+	}
+	return h
+}
+
+// isEnabled reports whether the optional setting is set and enabled, the
+// settings not set by a sub-handler are inherited from its parent.
+func isEnabled(setting *bool) bool {
+	return setting != nil && *setting
+}
+
+// joinPattern joins a group prefix with a route pattern.
+func joinPattern(prefix, pattern string) string {
+	if pattern == "" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(pattern, "/")
+}
@@ -0,0 +1,208 @@
+package gorest
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestGroupRoutes verifies that the routes of nested groups are listed with
+// their fully-resolved patterns.
+func TestGroupRoutes(t *testing.T) {
+	h := New()
+	h.RegisterRoute(NewRoute(Ping{}, "/ping"))
+
+	api := h.Group("/api/v1/")
+	api.RegisterRoute(NewRoute(testResourceWithGet{}, "/posts"))
+	api.Group("/users").RegisterRoute(NewRoute(testResourceWithGet{}, "/{id}"))
+	api.RegisterRoute(NewRoute(testResourceWithGet{}, ""))
+
+	expected := []string{"/ping", "/api/v1/posts", "/api/v1/", "/api/v1/users/{id}"}
+	routes := h.GetRoutes()
+	if len(routes) != len(expected) {
+		t.Fatalf("Unexpected routes len. Expected: %d - Found: %d.", len(expected), len(routes))
+	}
+	for i, pattern := range expected {
+		if routes[i].GetPattern() != pattern {
+			t.Fatalf("Unexpected route[%d] pattern. Expected: %s - Found: %s.", i, pattern, routes[i].GetPattern())
+		}
+	}
+
+	// The group routes are left untouched.
+	if pattern := api.routes[0].GetPattern(); pattern != "/posts" {
+		t.Fatalf("Unexpected group route pattern. Expected: %s - Found: %s.", "/posts", pattern)
+	}
+}
+
+// TestGroupServeHTTP verifies that group routes are served by the parent
+// handler, wrapped by the group middlewares.
+func TestGroupServeHTTP(t *testing.T) {
+	var trace []string
+	h := New()
+	h.Use(recordingMiddleware("root", &trace))
+
+	api := h.Group("/api", recordingMiddleware("api", &trace))
+	v1 := api.Group("/v1", recordingMiddleware("v1", &trace))
+
+	// Routes registered after the first request must be served too.
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	v1.RegisterRoute(NewRoute(testResourceWithGet{}, "/posts/{id}").Use(recordingMiddleware("route", &trace)))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/posts/1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+
+	expected := "root:before api:before v1:before route:before route:after v1:after api:after root:after"
+	if found := strings.Join(trace, " "); found != expected {
+		t.Fatalf("Unexpected middleware order. Expected: %s - Found: %s.", expected, found)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusNotFound, w.Code)
+	}
+}
+
+// TestMount verifies that an existing handler can be mounted into another
+// one while still being usable on its own.
+func TestMount(t *testing.T) {
+	var pattern string
+	sub := New()
+	sub.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		info, _ := GetRouteInfo(r)
+		pattern = info.Pattern
+		return http.StatusOK, nil
+	}), "/status"))
+
+	h := New()
+	h.Mount("/admin", sub)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/status", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if pattern != "/admin/status" {
+		t.Fatalf("Unexpected route pattern. Expected: %s - Found: %s.", "/admin/status", pattern)
+	}
+
+	w = httptest.NewRecorder()
+	sub.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if pattern != "/status" {
+		t.Fatalf("Unexpected route pattern. Expected: %s - Found: %s.", "/status", pattern)
+	}
+
+	// Mounted routes are also available through the mux router.
+	w = httptest.NewRecorder()
+	h.GetMuxRouter(nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/status", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+}
+
+// TestJoinPattern verifies the prefix and pattern joining.
+func TestJoinPattern(t *testing.T) {
+	tests := []struct{ prefix, pattern, expected string }{
+		{"/api", "/posts", "/api/posts"},
+		{"/api/", "/posts", "/api/posts"},
+		{"/api", "posts", "/api/posts"},
+		{"/api", "/", "/api/"},
+		{"/api", "", "/api"},
+		{"", "/posts", "/posts"},
+	}
+	for _, test := range tests {
+		if found := joinPattern(test.prefix, test.pattern); found != test.expected {
+			t.Fatalf("Unexpected pattern joining %q and %q. Expected: %s - Found: %s.", test.prefix, test.pattern, test.expected, found)
+		}
+	}
+}
+
+// TestGroupSettings verifies that group routes are served with the settings
+// of the group, inheriting the ones it does not configure.
+func TestGroupSettings(t *testing.T) {
+	encoderFunc := func(name string) Encoder {
+		return EncoderFunc(func(w io.Writer, v interface{}) error {
+			_, err := io.WriteString(w, name)
+			return err
+		})
+	}
+
+	h := New()
+	h.RegisterEncoder("text/x-root", "text/x-root", encoderFunc("root"))
+	h.RegisterRoute(NewRoute(testErrorResource{}, "/posts/{id}"))
+	h.RegisterRoute(NewRoute(&testValueResource{value: "value"}, "/value"))
+
+	api := h.Group("/api")
+	api.SetProblemDetails(true)
+	v1 := api.Group("/v1")
+	v1.RegisterEncoder("text/x-v1", "text/x-v1", encoderFunc("v1"))
+	v1.RegisterRoute(NewRoute(testErrorResource{}, "/posts/{id}"))
+	v1.RegisterRoute(NewRoute(&testValueResource{value: "value"}, "/value"))
+	legacy := api.Group("/legacy")
+	legacy.SetProblemDetails(false)
+	legacy.RegisterRoute(NewRoute(testErrorResource{}, "/posts/{id}"))
+
+	tests := []struct {
+		path        string
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"/posts/2", "", http.StatusNotFound, "application/json; charset=UTF-8", `{"status":"NAK","message":"not found"}`},
+		{"/api/v1/posts/2", "", http.StatusNotFound, "application/problem+json", `{"detail":"not found","instance":"/api/v1/posts/2","status":404,"title":"Not Found","type":"about:blank"}`},
+		{"/api/legacy/posts/2", "", http.StatusNotFound, "application/json; charset=UTF-8", `{"status":"NAK","message":"not found"}`},
+		{"/value", "text/x-root", http.StatusOK, "text/x-root", "root"},
+		{"/value", "text/x-v1", http.StatusNotAcceptable, "", ""},
+		{"/api/v1/value", "text/x-root", http.StatusOK, "text/x-root", "root"},
+		{"/api/v1/value", "text/x-v1", http.StatusOK, "text/x-v1", "v1"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		req.Header.Set("Accept", test.accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d.", test.path, test.code, w.Code)
+		}
+		if test.body == "" {
+			continue
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != test.contentType {
+			t.Fatalf("Unexpected Content-Type for %s. Expected: %s - Found: %s.", test.path, test.contentType, contentType)
+		}
+		if body := w.Body.String(); body != test.body {
+			t.Fatalf("Unexpected body for %s. Expected: %s - Found: %s.", test.path, test.body, body)
+		}
+	}
+}
+
+// TestGroupDevelopmentMode verifies that a group can disable the development
+// mode enabled on its parent.
+func TestGroupDevelopmentMode(t *testing.T) {
+	h := New()
+	h.SetLogger(log.New(io.Discard, "", 0))
+	h.SetDevelopmentMode(true)
+	api := h.Group("/api")
+	api.SetDevelopmentMode(false)
+	api.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		panic("something went wrong")
+	}), "/panic"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusInternalServerError, w.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package gorest
+
+import (
+	"context"
+	"net/http"
+)
+
+// routeInfoContextKey is the context key used to store the RouteInfo of the
+// request being served.
+type routeInfoContextKey struct{}
+
+// Middleware wraps a Handler adding behaviours like authentication, logging
+// or tracing. A middleware can short-circuit the chain returning its own
+// status code and Response without invoking the next Handler.
+type Middleware func(next Handler) Handler
+
+// RouteInfo describes the Route and the Resource method chosen to serve a
+// request, it is available to middlewares and resources via GetRouteInfo.
+type RouteInfo struct {
+	Route   *Route // Route matching the request.
+	Pattern string // Pattern of the matched route.
+	Method  string // HTTP method of the Resource handler serving the request.
+}
+
+// GetRouteInfo returns the RouteInfo of the request being served.
+func GetRouteInfo(r *http.Request) (RouteInfo, bool) {
+	info, ok := r.Context().Value(routeInfoContextKey{}).(RouteInfo)
+	return info, ok
+}
+
+// withRouteInfo returns a copy of the request carrying provided RouteInfo.
+func withRouteInfo(r *http.Request, info RouteInfo) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), routeInfoContextKey{}, info))
+}
+
+// Use appends provided middlewares to the ones wrapping every route of the
+// handler. Handler middlewares run before the Route ones, each list in the
+// order they have been added.
+func (h *RestHandler) Use(middleware ...Middleware) {
+	h.middleware = append(h.middleware, middleware...)
+	h.resetRouter()
+}
+
+// chain wraps the handler with provided middlewares, so that the first one
+// is the outermost.
+func chain(handler Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,145 @@
+package gorest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordingMiddleware returns a middleware appending its name to the trace
+// before and after invoking the next handler.
+func recordingMiddleware(name string, trace *[]string) Middleware {
+	return func(next Handler) Handler {
+		return func(r *http.Request) (int, Response) {
+			*trace = append(*trace, name+":before")
+			code, response := next(r)
+			*trace = append(*trace, name+":after")
+			return code, response
+		}
+	}
+}
+
+// TestMiddlewareOrder verifies that handler middlewares wrap route ones, each
+// in the order they have been registered.
+func TestMiddlewareOrder(t *testing.T) {
+	var trace []string
+	h := New()
+	h.Use(recordingMiddleware("h1", &trace), recordingMiddleware("h2", &trace))
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		trace = append(trace, "resource")
+		return http.StatusOK, nil
+	}), "/").Use(recordingMiddleware("r1", &trace)).Use(recordingMiddleware("r2", &trace)))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+
+	expected := "h1:before h2:before r1:before r2:before resource r2:after r1:after h2:after h1:after"
+	if found := strings.Join(trace, " "); found != expected {
+		t.Fatalf("Unexpected middleware order. Expected: %s - Found: %s.", expected, found)
+	}
+}
+
+// TestRouteMiddlewareAddedWhileServing verifies that the middlewares added to
+// the routes once the requests are being served are applied, including the
+// routes of the groups.
+func TestRouteMiddlewareAddedWhileServing(t *testing.T) {
+	var trace []string
+	h := New()
+	route := NewRoute(testResourceWithGet{}, "/")
+	h.RegisterRoute(route)
+	groupRoute := NewRoute(testResourceWithGet{}, "/posts")
+	h.Group("/api").RegisterRoute(groupRoute)
+
+	for _, path := range []string{"/", "/api/posts"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	route.Use(recordingMiddleware("route", &trace))
+	groupRoute.Use(recordingMiddleware("group", &trace))
+	for _, path := range []string{"/", "/api/posts"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := "route:before route:after group:before group:after"
+	if found := strings.Join(trace, " "); found != expected {
+		t.Fatalf("Unexpected middlewares. Expected: %s - Found: %s.", expected, found)
+	}
+}
+
+// TestMiddlewareShortCircuit verifies that a middleware can reply without
+// invoking the resource.
+func TestMiddlewareShortCircuit(t *testing.T) {
+	invoked := false
+	h := New()
+	h.Use(func(next Handler) Handler {
+		return func(r *http.Request) (int, Response) {
+			if r.Header.Get("Authorization") == "" {
+				return http.StatusUnauthorized, NewFailResponse("unauthorized")
+			}
+			return next(r)
+		}
+	})
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		invoked = true
+		return http.StatusOK, nil
+	}), "/"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusUnauthorized, w.Code)
+	}
+	if w.Body.String() != `{"status":"NAK","message":"unauthorized"}` {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", `{"status":"NAK","message":"unauthorized"}`, w.Body.String())
+	}
+	if invoked {
+		t.Fatalf("The resource should not have been invoked.")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "token")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if !invoked {
+		t.Fatalf("The resource should have been invoked.")
+	}
+}
+
+// TestMiddlewareRouteInfo verifies that middlewares can access the route
+// pattern and the chosen method.
+func TestMiddlewareRouteInfo(t *testing.T) {
+	var info RouteInfo
+	var found bool
+	h := New()
+	h.Use(func(next Handler) Handler {
+		return func(r *http.Request) (int, Response) {
+			info, found = GetRouteInfo(r)
+			return next(r)
+		}
+	})
+	h.RegisterRoute(NewRoute(testResourceWithPost{}, "/posts/{id}"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/posts/1", nil))
+	if !found {
+		t.Fatalf("RouteInfo should be available to middlewares.")
+	}
+	if info.Pattern != "/posts/{id}" {
+		t.Fatalf("Unexpected pattern. Expected: %s - Found: %s.", "/posts/{id}", info.Pattern)
+	}
+	if info.Method != http.MethodPost {
+		t.Fatalf("Unexpected method. Expected: %s - Found: %s.", http.MethodPost, info.Method)
+	}
+	if info.Route == nil || info.Route.GetPattern() != "/posts/{id}" {
+		t.Fatalf("Unexpected route: %+v.", info.Route)
+	}
+
+	if _, ok := GetRouteInfo(httptest.NewRequest(http.MethodGet, "/", nil)); ok {
+		t.Fatalf("RouteInfo should not be available outside gorest.")
+	}
+}
@@ -0,0 +1,73 @@
+package gorest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// qualityValue is an element of a header listing values weighted by quality,
+// like Accept and Accept-Encoding.
+type qualityValue struct {
+	value string
+	q     float64
+}
+
+// parseQualityValues parses a comma separated list of values with optional
+// q parameters; the values are lowercased and their other parameters dropped.
+// The result is sorted by descending quality, preserving the header order for
+// equal qualities.
+func parseQualityValues(header string) []qualityValue {
+	var values []qualityValue
+	for _, element := range strings.Split(header, ",") {
+		parts := strings.Split(element, ";")
+		value := strings.ToLower(strings.TrimSpace(parts[0]))
+		if value == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(strings.ToLower(param), "q=") {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(param[2:]), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+		values = append(values, qualityValue{value: value, q: q})
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].q > values[j].q
+	})
+	return values
+}
+
+// mediaRangeQuality returns the quality the client assigned to the media
+// type, using the most specific matching media range; -1 is returned when no
+// range matches.
+func mediaRangeQuality(ranges []qualityValue, mediaType string) float64 {
+	q, specificity := -1.0, -1
+	mainType := strings.SplitN(mediaType, "/", 2)[0]
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.value == mediaType:
+			s = 2
+		case r.value == mainType+"/*":
+			s = 1
+		case r.value == "*/*" || r.value == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
@@ -0,0 +1,44 @@
+package gorest
+
+import "testing"
+
+// TestParseQualityValues verifies the parsing of quality weighted headers.
+func TestParseQualityValues(t *testing.T) {
+	values := parseQualityValues("text/html;level=1, application/json;q=0.5, */*;q=0.1, Text/CSV, bad;q=x")
+
+	expected := []qualityValue{
+		{"text/html", 1},
+		{"text/csv", 1},
+		{"application/json", 0.5},
+		{"*/*", 0.1},
+		{"bad", 0},
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("Unexpected values count. Expected: %d - Found: %d.", len(expected), len(values))
+	}
+	for i := range expected {
+		if values[i] != expected[i] {
+			t.Fatalf("Unexpected value. Expected: %v - Found: %v.", expected[i], values[i])
+		}
+	}
+}
+
+// TestMediaRangeQuality verifies that the most specific media range is used.
+func TestMediaRangeQuality(t *testing.T) {
+	ranges := parseQualityValues("text/*;q=0.3, text/csv;q=0.7, */*;q=0.1")
+
+	tests := map[string]float64{
+		"text/csv":         0.7,
+		"text/plain":       0.3,
+		"application/json": 0.1,
+	}
+	for mediaType, expected := range tests {
+		if q := mediaRangeQuality(ranges, mediaType); q != expected {
+			t.Fatalf("Unexpected quality for %s. Expected: %v - Found: %v.", mediaType, expected, q)
+		}
+	}
+
+	if q := mediaRangeQuality(parseQualityValues("text/csv"), "application/json"); q != -1 {
+		t.Fatalf("Unexpected quality. Expected: -1 - Found: %v.", q)
+	}
+}
@@ -0,0 +1,210 @@
+package gorest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// paramsContextKey is the context key used to store the path parameters
+// matched by the native router.
+type paramsContextKey struct{}
+
+// paramConstraints defines the named constraints that can be used in place of
+// a regular expression in the route patterns, e.g. "/posts/{id:int}". The
+// int constraint does not bound the number of digits: the values overflowing
+// the type make the accessors return a ParamError, mapped to 404 Not Found by
+// the DefaultErrorMapper.
+var paramConstraints = map[string]string{
+	"int":  `-?[0-9]+`,
+	"uuid": `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	"slug": `[a-z0-9]+(?:-[a-z0-9]+)*`,
+}
+
+var slugRegexp = regexp.MustCompile(`^` + paramConstraints["slug"] + `$`)
+
+// ParamError is returned by the path parameter accessors when the parameter
+// is missing or cannot be converted to the required type.
+type ParamError struct {
+	Name  string // Name of the parameter.
+	Value string // Raw value of the parameter.
+	Type  string // Type requested for the conversion.
+	Err   error  // Conversion error, if any.
+}
+
+// Error returns the description of the parameter error.
+func (e *ParamError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("path parameter %q not found", e.Name)
+	}
+	return fmt.Sprintf("invalid %s path parameter %q (%q): %s", e.Type, e.Name, e.Value, e.Err.Error())
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// PathParams returns the path parameters matched for the provided request,
+// both by the native router and by a Gorilla Mux router obtained with
+// GetMuxRouter. The map is empty if no parameter has been matched.
+func PathParams(r *http.Request) map[string]string {
+	if params, ok := r.Context().Value(paramsContextKey{}).(map[string]string); ok {
+		return params
+	}
+	if params := mux.Vars(r); params != nil {
+		return params
+	}
+	return map[string]string{}
+}
+
+// PathParam returns the string value of the named path parameter.
+func PathParam(r *http.Request, name string) (string, error) {
+	value, ok := PathParams(r)[name]
+	if !ok {
+		return "", &ParamError{Name: name, Type: "string"}
+	}
+	return value, nil
+}
+
+// PathParamInt returns the value of the named path parameter as int.
+func PathParamInt(r *http.Request, name string) (int, error) {
+	value, err := PathParam(r, name)
+	if err != nil {
+		return 0, err
+	}
+	converted, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ParamError{Name: name, Value: value, Type: "int", Err: err}
+	}
+	return converted, nil
+}
+
+// PathParamInt64 returns the value of the named path parameter as int64.
+func PathParamInt64(r *http.Request, name string) (int64, error) {
+	value, err := PathParam(r, name)
+	if err != nil {
+		return 0, err
+	}
+	converted, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &ParamError{Name: name, Value: value, Type: "int64", Err: err}
+	}
+	return converted, nil
+}
+
+// PathParamUUID returns the value of the named path parameter as UUID.
+func PathParamUUID(r *http.Request, name string) (UUID, error) {
+	value, err := PathParam(r, name)
+	if err != nil {
+		return UUID{}, err
+	}
+	converted, err := ParseUUID(value)
+	if err != nil {
+		return UUID{}, &ParamError{Name: name, Value: value, Type: "uuid", Err: err}
+	}
+	return converted, nil
+}
+
+// PathParamSlug returns the value of the named path parameter verifying it is
+// a valid slug, made of lowercase alphanumeric words separated by dashes.
+func PathParamSlug(r *http.Request, name string) (string, error) {
+	value, err := PathParam(r, name)
+	if err != nil {
+		return "", err
+	}
+	if !slugRegexp.MatchString(value) {
+		return "", &ParamError{Name: name, Value: value, Type: "slug", Err: fmt.Errorf("not a valid slug")}
+	}
+	return value, nil
+}
+
+// UUID is a RFC 4122 universally unique identifier.
+type UUID [16]byte
+
+// ParseUUID parses the canonical textual representation of a UUID, in the
+// form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("invalid UUID format")
+	}
+	digits := strings.Replace(s, "-", "", -1)
+	if len(digits) != 32 {
+		return u, fmt.Errorf("invalid UUID format")
+	}
+	if _, err := hex.Decode(u[:], []byte(digits)); err != nil {
+		return u, fmt.Errorf("invalid UUID format: %s", err.Error())
+	}
+	return u, nil
+}
+
+// String returns the canonical textual representation of the UUID.
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// parseParamSegment verifies whether the pattern segment is a parameter
+// definition, in the form "{name}" or "{name:constraint}", and returns its
+// name and expanded constraint.
+func parseParamSegment(segment string) (string, string, bool) {
+	if len(segment) < 3 || segment[0] != '{' || segment[len(segment)-1] != '}' {
+		return "", "", false
+	}
+	definition := segment[1 : len(segment)-1]
+
+	name, constraint := definition, ""
+	if i := strings.Index(definition, ":"); i >= 0 {
+		name, constraint = definition[:i], definition[i+1:]
+	}
+	if expanded, ok := paramConstraints[constraint]; ok {
+		constraint = expanded
+	}
+	return name, constraint, true
+}
+
+// validatePattern verifies that the constraints of the route pattern are
+// valid regular expressions.
+func validatePattern(pattern string) error {
+	for _, segment := range splitPath(pattern) {
+		name, constraint, ok := parseParamSegment(segment)
+		if !ok || constraint == "" {
+			continue
+		}
+		if _, err := regexp.Compile("^(?:" + constraint + ")$"); err != nil {
+			return fmt.Errorf("invalid constraint of path parameter %q: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// mustValidatePattern panics if the route pattern is not valid, reporting
+// the programming error when the route is registered instead of when it is
+// served.
+func mustValidatePattern(pattern string) {
+	if err := validatePattern(pattern); err != nil {
+		panic(fmt.Sprintf("gorest: invalid route pattern %q: %s", pattern, err.Error()))
+	}
+}
+
+// expandPattern replaces the named constraints of a route pattern with the
+// corresponding regular expressions, so that Gorilla Mux understands them.
+func expandPattern(pattern string) string {
+	segments := splitPath(pattern)
+	for i, segment := range segments {
+		if name, constraint, ok := parseParamSegment(segment); ok && constraint != "" {
+			segments[i] = "{" + name + ":" + constraint + "}"
+		}
+	}
+	expanded := strings.Join(segments, "/")
+	if strings.HasPrefix(pattern, "/") {
+		expanded = "/" + expanded
+	}
+	return expanded
+}
@@ -0,0 +1,191 @@
+package gorest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// withParams returns a copy of the request carrying provided path parameters.
+func withParams(r *http.Request, params map[string]string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), paramsContextKey{}, params))
+}
+
+// TestPathParamAccessors verifies the typed path parameter accessors.
+func TestPathParamAccessors(t *testing.T) {
+	r := withParams(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{
+		"id":   "42",
+		"big":  "9223372036854775807",
+		"uuid": "123e4567-e89b-12d3-a456-426614174000",
+		"slug": "hello-world",
+		"bad":  "not an int",
+	})
+
+	if value, err := PathParam(r, "id"); err != nil || value != "42" {
+		t.Fatalf("Unexpected string param. Expected: %s - Found: %s (%v).", "42", value, err)
+	}
+	if value, err := PathParamInt(r, "id"); err != nil || value != 42 {
+		t.Fatalf("Unexpected int param. Expected: %d - Found: %d (%v).", 42, value, err)
+	}
+	if value, err := PathParamInt64(r, "big"); err != nil || value != 9223372036854775807 {
+		t.Fatalf("Unexpected int64 param. Expected: %d - Found: %d (%v).", int64(9223372036854775807), value, err)
+	}
+	if value, err := PathParamUUID(r, "uuid"); err != nil || value.String() != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Fatalf("Unexpected uuid param. Expected: %s - Found: %s (%v).", "123e4567-e89b-12d3-a456-426614174000", value, err)
+	}
+	if value, err := PathParamSlug(r, "slug"); err != nil || value != "hello-world" {
+		t.Fatalf("Unexpected slug param. Expected: %s - Found: %s (%v).", "hello-world", value, err)
+	}
+
+	if _, err := PathParam(r, "missing"); err == nil {
+		t.Fatalf("An error was expected for missing param. Found nil.")
+	}
+	if _, err := PathParamInt(r, "bad"); err == nil {
+		t.Fatalf("An error was expected for invalid int param. Found nil.")
+	} else if paramErr, ok := err.(*ParamError); !ok || paramErr.Name != "bad" || paramErr.Type != "int" {
+		t.Fatalf("Unexpected error: %+v.", err)
+	}
+	if _, err := PathParamInt64(r, "bad"); err == nil {
+		t.Fatalf("An error was expected for invalid int64 param. Found nil.")
+	}
+	if _, err := PathParamUUID(r, "bad"); err == nil {
+		t.Fatalf("An error was expected for invalid uuid param. Found nil.")
+	}
+	if _, err := PathParamSlug(r, "bad"); err == nil {
+		t.Fatalf("An error was expected for invalid slug param. Found nil.")
+	}
+}
+
+// TestParseUUID verifies UUID parsing and formatting.
+func TestParseUUID(t *testing.T) {
+	u, err := ParseUUID("123E4567-E89B-12D3-A456-426614174000")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	if u.String() != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Fatalf("Unexpected UUID. Expected: %s - Found: %s.", "123e4567-e89b-12d3-a456-426614174000", u.String())
+	}
+
+	invalid := []string{
+		"",
+		"123e4567e89b12d3a456426614174000",
+		"123e4567-e89b-12d3-a456-42661417400z",
+		"12345678-1234-1234-1234-1234567890--",
+	}
+	for _, s := range invalid {
+		if _, err := ParseUUID(s); err == nil {
+			t.Fatalf("An error was expected parsing %q. Found nil.", s)
+		}
+	}
+}
+
+// TestRouteConstraints verifies that parameters not satisfying the pattern
+// constraints result in 404 Not Found, both with the native and the mux
+// routers.
+func TestRouteConstraints(t *testing.T) {
+	var id int
+	h := New()
+	h.SetRoutes([]*Route{
+		NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+			var err error
+			if id, err = PathParamInt(r, "id"); err != nil {
+				return http.StatusBadRequest, nil
+			}
+			return http.StatusOK, nil
+		}), "/posts/{id:int}"),
+		NewRoute(testResourceWithPost{}, "/posts/{slug:slug}"),
+		NewRoute(testResourceWithGet{}, "/codes/{code:[A-Z]{3}}"),
+	})
+
+	handlers := map[string]http.Handler{
+		"native": h,
+		"mux":    h.GetMuxRouter(nil),
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{http.MethodGet, "/posts/12", http.StatusOK},
+		{http.MethodPost, "/posts/a-slug", http.StatusOK},
+		{http.MethodGet, "/posts/Not_A_Slug", http.StatusNotFound},
+		{http.MethodGet, "/codes/ABC", http.StatusOK},
+		{http.MethodGet, "/codes/ABCD", http.StatusNotFound},
+		{http.MethodGet, "/codes/abc", http.StatusNotFound},
+	}
+
+	for name, handler := range handlers {
+		for _, test := range tests {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+			if w.Code != test.code {
+				t.Fatalf("Unexpected status code for %s %s (%s). Expected: %d - Found: %d.", test.method, test.path, name, test.code, w.Code)
+			}
+		}
+
+		id = 0
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/-7", nil))
+		if id != -7 {
+			t.Fatalf("Unexpected id (%s). Expected: %d - Found: %d.", name, -7, id)
+		}
+	}
+}
+
+// TestInvalidRouteConstraint verifies that routes with invalid constraints
+// are rejected when registered.
+func TestInvalidRouteConstraint(t *testing.T) {
+	if err := validatePattern("/x/{id:[0-9]+}/{slug:slug}"); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+
+	registrations := map[string]func(h *RestHandler){
+		"RegisterRoute": func(h *RestHandler) { h.RegisterRoute(NewRoute(testResourceWithGet{}, "/x/{id:[0-9}")) },
+		"SetRoutes":     func(h *RestHandler) { h.SetRoutes([]*Route{NewRoute(testResourceWithGet{}, "/x/{id:[0-9}")}) },
+		"Group":         func(h *RestHandler) { h.Group("/x/{id:[0-9}") },
+	}
+	for name, register := range registrations {
+		func() {
+			defer func() {
+				if recovered := recover(); recovered == nil {
+					t.Fatalf("A panic was expected registering the route using %s.", name)
+				}
+			}()
+			register(New())
+		}()
+	}
+}
+
+// TestExpandPattern verifies the named constraints expansion.
+func TestExpandPattern(t *testing.T) {
+	tests := map[string]string{
+		"/posts/{id}":            "/posts/{id}",
+		"/posts/{id:int}":        "/posts/{id:" + paramConstraints["int"] + "}",
+		"/posts/{id:[a-z]+}/x":   "/posts/{id:[a-z]+}/x",
+		"/{slug:slug}/{u:uuid}/": "/{slug:" + paramConstraints["slug"] + "}/{u:" + paramConstraints["uuid"] + "}/",
+	}
+	for pattern, expected := range tests {
+		if found := expandPattern(pattern); found != expected {
+			t.Fatalf("Unexpected expanded pattern. Expected: %s - Found: %s.", expected, found)
+		}
+	}
+}
+
+// TestRouteIntOverflow verifies that an int parameter satisfying the route
+// constraint but overflowing the type results in 404 Not Found.
+func TestRouteIntOverflow(t *testing.T) {
+	h := New()
+	h.RegisterRoute(NewRoute(testErrPostResource(func(r *http.Request) (Response, error) {
+		if _, err := PathParamInt(r, "id"); err != nil {
+			return nil, err
+		}
+		return NewStandardResponse(), nil
+	}), "/posts/{id:int}"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts/99999999999999999999", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusNotFound, w.Code)
+	}
+}
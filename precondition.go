@@ -0,0 +1,216 @@
+package gorest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrPreconditionFailed is returned when a precondition of the request does
+// not hold for the current version of the resource.
+var ErrPreconditionFailed = NewError(http.StatusPreconditionFailed, "precondition failed")
+
+// ErrPreconditionRequired is returned when a route requires the unsafe
+// requests to be conditional and the request is not.
+var ErrPreconditionRequired = NewError(http.StatusPreconditionRequired, "precondition required")
+
+// Version identifies the current state of a resource, it is used to evaluate
+// the conditional requests.
+type Version struct {
+	ETag         string    // Entity tag, with or without the quotes.
+	Weak         bool      // Whether the entity tag is weak.
+	LastModified time.Time // Modification time, the zero time if unknown.
+}
+
+// VersionSupported is the interface implemented by the resources exposing the
+// version of their current state, it is invoked before the resource methods
+// to evaluate the request preconditions. GetVersion returns ErrNotFound, or
+// any Error with code 404, when the resource does not exist yet.
+// The version ETag and Last-Modified are sent with the GET and HEAD responses,
+// sparing the hashing of the body, and allow replying 304 Not Modified before
+// the response is built; it should therefore be cheap, e.g. reading a version
+// column.
+type VersionSupported interface {
+	GetVersion(r *http.Request) (Version, error)
+}
+
+// RequirePreconditions requires the PUT, PATCH and DELETE requests to the
+// route to be conditional, using If-Match or If-Unmodified-Since, replying
+// with 428 Precondition Required otherwise. The resource must implement
+// VersionSupported, the preconditions could not be evaluated otherwise: the
+// method panics if it does not, reporting the programming error when the
+// route is defined.
+// The route itself is returned to allow chaining calls.
+func (r *Route) RequirePreconditions() *Route {
+	if _, ok := r.resource.(VersionSupported); !ok {
+		panic(fmt.Sprintf("gorest: route %q requires preconditions but its resource does not implement VersionSupported", r.pattern))
+	}
+	r.requirePreconditions = true
+	r.resetRouters()
+	return r
+}
+
+// isUnsafeMethod verifies whether the method modifies the state of the
+// resource, requiring optimistic concurrency control.
+func isUnsafeMethod(method string) bool {
+	return method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+}
+
+// preconditionHandler wraps the resource handler evaluating the request
+// preconditions against the version of the resource, if it implements
+// VersionSupported. The If-Match and If-Unmodified-Since preconditions of the
+// unsafe requests are enforced, while the GET and HEAD requests whose
+// If-None-Match or If-Modified-Since precondition holds are replied with 304
+// Not Modified without invoking the resource. The version of the resource is
+// stored into current for the GET and HEAD requests.
+func (h *RestHandler) preconditionHandler(handler Handler, route *Route, method string, current *Version) Handler {
+	res, ok := route.GetResource().(VersionSupported)
+	if isGetOrHead(method) && ok {
+		return func(r *http.Request) (int, Response) {
+			version, err := res.GetVersion(r)
+			if err != nil {
+				// The resource method reports the error.
+				return handler(r)
+			}
+			*current = version
+
+			header := make(http.Header)
+			setVersionHeaders(header, version)
+			if notModified(r, header) {
+				return http.StatusNotModified, nil
+			}
+			return handler(r)
+		}
+	}
+	if !isUnsafeMethod(method) || !ok {
+		return handler
+	}
+
+	return func(r *http.Request) (int, Response) {
+		ifMatch := r.Header.Get("If-Match")
+		ifUnmodifiedSince := r.Header.Get("If-Unmodified-Since")
+		if ifMatch == "" && ifUnmodifiedSince == "" {
+			if route.requirePreconditions {
+				return h.mapError(r, ErrPreconditionRequired)
+			}
+			return handler(r)
+		}
+
+		version, err := res.GetVersion(r)
+		exists := true
+		var gorestErr *Error
+		if errors.As(err, &gorestErr) && gorestErr.Code == http.StatusNotFound {
+			exists = false
+		} else if err != nil {
+			return h.mapError(r, err)
+		}
+
+		if ifMatch != "" {
+			if !exists || !matchETags(ifMatch, version, true) {
+				return h.mapError(r, ErrPreconditionFailed)
+			}
+		} else if exists && !version.LastModified.IsZero() {
+			if since, err := http.ParseTime(ifUnmodifiedSince); err == nil && version.LastModified.Truncate(time.Second).After(since) {
+				return h.mapError(r, ErrPreconditionFailed)
+			}
+		}
+		return handler(r)
+	}
+}
+
+// setVersionHeaders sets the ETag and Last-Modified headers of the version,
+// if known.
+func setVersionHeaders(header http.Header, version Version) {
+	if version.ETag != "" {
+		header.Set("ETag", formatETag(version))
+	}
+	if !version.LastModified.IsZero() {
+		header.Set("Last-Modified", version.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified evaluates the If-None-Match, or If-Modified-Since when absent,
+// precondition of a GET or HEAD request against the ETag and Last-Modified
+// headers of the current representation; it returns true when the client
+// representation is still valid.
+func notModified(r *http.Request, header http.Header) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		var version Version
+		if tags := parseETags(header.Get("ETag")); len(tags) > 0 {
+			version = Version{ETag: tags[0].tag, Weak: tags[0].weak}
+		}
+		return matchETags(ifNoneMatch, version, false)
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if ifModifiedSince == "" || err != nil {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	return err == nil && !lastModified.After(since)
+}
+
+// formatETag returns the entity tag of the version quoted according to
+// RFC 9110, with the W/ prefix if weak.
+func formatETag(version Version) string {
+	etag := quoteETag(version.ETag)
+	if version.Weak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// quoteETag quotes the entity tag if needed.
+func quoteETag(etag string) string {
+	if len(etag) >= 2 && strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// entityTag is an entity tag listed in the If-Match and If-None-Match
+// headers.
+type entityTag struct {
+	tag  string // Quoted opaque tag.
+	weak bool
+}
+
+// parseETags parses a comma separated list of entity tags.
+func parseETags(header string) []entityTag {
+	var tags []entityTag
+	for _, element := range strings.Split(header, ",") {
+		element = strings.TrimSpace(element)
+		weak := strings.HasPrefix(element, "W/")
+		element = strings.TrimPrefix(element, "W/")
+		if element != "" {
+			tags = append(tags, entityTag{tag: quoteETag(element), weak: weak})
+		}
+	}
+	return tags
+}
+
+// matchETags verifies whether the header, listing entity tags or "*",
+// matches the version; the strong comparison never matches weak tags. The
+// content coding suffix of the ETags of compressed responses is ignored.
+func matchETags(header string, version Version, strong bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if version.ETag == "" {
+		return false
+	}
+
+	current := quoteETag(version.ETag)
+	for _, tag := range parseETags(header) {
+		if tag.tag != current && trimEncodingETag(tag.tag) != current {
+			continue
+		}
+		if !strong || (!tag.weak && !version.Weak) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package gorest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testVersionedResource exposes its version and records the updates.
+type testVersionedResource struct {
+	version    Version
+	versionErr error
+	updated    bool
+	served     bool
+}
+
+func (t *testVersionedResource) GetVersion(r *http.Request) (Version, error) {
+	return t.version, t.versionErr
+}
+
+func (t *testVersionedResource) Get(r *http.Request) (int, Response) {
+	t.served = true
+	response := NewStandardResponse()
+	response.SetBody([]byte("body"))
+	return http.StatusOK, response
+}
+
+func (t *testVersionedResource) Put(r *http.Request) (int, Response) {
+	t.updated = true
+	return http.StatusOK, nil
+}
+
+func (t *testVersionedResource) Delete(r *http.Request) (int, Response) {
+	t.updated = true
+	return http.StatusNoContent, nil
+}
+
+// TestPreconditions verifies the If-Match and If-Unmodified-Since evaluation
+// on the unsafe methods.
+func TestPreconditions(t *testing.T) {
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		method  string
+		version Version
+		err     error
+		headers map[string]string
+		code    int
+	}{
+		{"match", http.MethodPut, Version{ETag: "v1"}, nil, map[string]string{"If-Match": `"v0", "v1"`}, http.StatusOK},
+		{"mismatch", http.MethodPut, Version{ETag: "v1"}, nil, map[string]string{"If-Match": `"v0"`}, http.StatusPreconditionFailed},
+		{"weak", http.MethodPut, Version{ETag: "v1", Weak: true}, nil, map[string]string{"If-Match": `W/"v1"`}, http.StatusPreconditionFailed},
+		{"any", http.MethodDelete, Version{ETag: "v1"}, nil, map[string]string{"If-Match": "*"}, http.StatusNoContent},
+		{"missing", http.MethodPut, Version{}, ErrNotFound, map[string]string{"If-Match": "*"}, http.StatusPreconditionFailed},
+		{"create", http.MethodPut, Version{}, ErrNotFound, nil, http.StatusOK},
+		{"unmodified", http.MethodPut, Version{LastModified: modified}, nil, map[string]string{"If-Unmodified-Since": modified.Format(http.TimeFormat)}, http.StatusOK},
+		{"modified", http.MethodPut, Version{LastModified: modified}, nil, map[string]string{"If-Unmodified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusPreconditionFailed},
+		{"if-match first", http.MethodPut, Version{ETag: "v1", LastModified: modified}, nil, map[string]string{"If-Match": `"v1"`, "If-Unmodified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"safe", http.MethodGet, Version{ETag: "v1"}, nil, map[string]string{"If-Match": `"v0"`}, http.StatusOK},
+		{"error", http.MethodPut, Version{}, ErrForbidden, map[string]string{"If-Match": `"v1"`}, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		res := &testVersionedResource{version: test.version, versionErr: test.err}
+		h := New()
+		h.RegisterRoute(NewRoute(res, "/"))
+
+		req := httptest.NewRequest(test.method, "/", nil)
+		for key, value := range test.headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d.", test.name, test.code, w.Code)
+		}
+		if failed := test.code >= http.StatusBadRequest; test.method != http.MethodGet && failed == res.updated {
+			t.Fatalf("Unexpected update for %s. Expected: %t - Found: %t.", test.name, !failed, res.updated)
+		}
+	}
+}
+
+// TestRequirePreconditions verifies that 428 is returned for unconditional
+// unsafe requests to the routes requiring preconditions.
+func TestRequirePreconditions(t *testing.T) {
+	res := &testVersionedResource{version: Version{ETag: "v1"}}
+	h := New()
+	h.RegisterRoute(NewRoute(res, "/").RequirePreconditions())
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", nil))
+	if w.Code != http.StatusPreconditionRequired {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusPreconditionRequired, w.Code)
+	}
+	if res.updated {
+		t.Fatalf("Resource should not have been updated.")
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/", nil)
+	req.Header.Set("If-Match", `"v1"`)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+}
+
+// TestRequirePreconditionsUnversioned verifies that requiring preconditions
+// on a route whose resource does not expose its version panics.
+func TestRequirePreconditionsUnversioned(t *testing.T) {
+	defer func() {
+		if recovered := recover(); recovered == nil {
+			t.Fatalf("A panic was expected for a resource not implementing VersionSupported.")
+		}
+	}()
+	NewRoute(testResourceWithPut{}, "/").RequirePreconditions()
+}
+
+// TestParseETags verifies the parsing of the entity tag lists.
+func TestParseETags(t *testing.T) {
+	tags := parseETags(`"a", W/"b", c`)
+	expected := []entityTag{{`"a"`, false}, {`"b"`, true}, {`"c"`, false}}
+	if len(tags) != len(expected) {
+		t.Fatalf("Unexpected tags count. Expected: %d - Found: %d.", len(expected), len(tags))
+	}
+	for i := range expected {
+		if tags[i] != expected[i] {
+			t.Fatalf("Unexpected tag. Expected: %v - Found: %v.", expected[i], tags[i])
+		}
+	}
+
+	if etag := formatETag(Version{ETag: "v1", Weak: true}); etag != `W/"v1"` {
+		t.Fatalf("Unexpected ETag. Expected: W/\"v1\" - Found: %s.", etag)
+	}
+}
+
+// TestConditionalGet verifies that the GET requests are replied with 304 Not
+// Modified, without invoking the resource, according to its version.
+func TestConditionalGet(t *testing.T) {
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		version Version
+		headers map[string]string
+		code    int
+	}{
+		{"match", Version{ETag: "v1"}, map[string]string{"If-None-Match": `"v1"`}, http.StatusNotModified},
+		{"list", Version{ETag: "v1"}, map[string]string{"If-None-Match": `"v0", W/"v1"`}, http.StatusNotModified},
+		{"weak", Version{ETag: "v1", Weak: true}, map[string]string{"If-None-Match": `"v1"`}, http.StatusNotModified},
+		{"any", Version{ETag: "v1"}, map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"mismatch", Version{ETag: "v1"}, map[string]string{"If-None-Match": `"v0"`}, http.StatusOK},
+		{"not modified", Version{LastModified: modified}, map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified", Version{LastModified: modified}, map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"if-none-match first", Version{ETag: "v1", LastModified: modified}, map[string]string{"If-None-Match": `"v0"`, "If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusOK},
+		{"unconditional", Version{ETag: "v1"}, nil, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		res := &testVersionedResource{version: test.version}
+		h := New()
+		h.RegisterRoute(NewRoute(res, "/"))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for key, value := range test.headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != test.code {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d.", test.name, test.code, w.Code)
+		}
+		if served := test.code == http.StatusOK; served != res.served {
+			t.Fatalf("Unexpected resource invocation for %s. Expected: %t - Found: %t.", test.name, served, res.served)
+		}
+
+		headers := w.Result().Header
+		if test.version.ETag != "" {
+			if etag := headers.Get("ETag"); etag != formatETag(test.version) {
+				t.Fatalf("Unexpected ETag for %s. Expected: %s - Found: %s.", test.name, formatETag(test.version), etag)
+			}
+		}
+		if !test.version.LastModified.IsZero() {
+			if lastModified := headers.Get("Last-Modified"); lastModified != modified.Format(http.TimeFormat) {
+				t.Fatalf("Unexpected Last-Modified for %s. Expected: %s - Found: %s.", test.name, modified.Format(http.TimeFormat), lastModified)
+			}
+		}
+	}
+}
+
+// TestConditionalHead verifies that the HEAD requests are replied with 304
+// Not Modified according to the version of the resource, without the
+// Content-Length of the body.
+func TestConditionalHead(t *testing.T) {
+	res := &testVersionedResource{version: Version{ETag: "v1"}}
+	h := New()
+	h.RegisterRoute(NewRoute(res, "/"))
+
+	req := httptest.NewRequest(http.MethodHead, "/", nil)
+	req.Header.Set("If-None-Match", `"v1"`)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusNotModified, w.Code)
+	}
+	if length, ok := w.Result().Header["Content-Length"]; ok {
+		t.Fatalf("Unexpected Content-Length. Expected: none - Found: %v.", length)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/", nil))
+	if length := w.Result().Header.Get("Content-Length"); w.Code != http.StatusOK || length != "4" {
+		t.Fatalf("Unexpected Content-Length. Expected: 4 - Found: %s.", length)
+	}
+}
+
+// TestConditionalGetBodyETag verifies the If-None-Match lists against the
+// ETags computed from the body.
+func TestConditionalGetBodyETag(t *testing.T) {
+	h := New()
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		response := NewStandardResponse()
+		response.SetBody([]byte("body"))
+		return http.StatusOK, response
+	}), "/"))
+
+	etag := quoteETag(getETag([]byte("body")))
+	for _, ifNoneMatch := range []string{etag, `"other", ` + etag, "W/" + etag, "*"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d.", ifNoneMatch, http.StatusNotModified, w.Code)
+		}
+	}
+}
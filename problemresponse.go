@@ -0,0 +1,103 @@
+package gorest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType is the media type of the Problem Details responses.
+const ProblemContentType = "application/problem+json"
+
+// ProblemResponse is a RFC 9457 (formerly RFC 7807) Problem Details response
+// providing machine-readable details of an error.
+type ProblemResponse struct {
+	Type     string // URI reference identifying the problem type.
+	Title    string // Short, human-readable summary of the problem type.
+	Status   int    // HTTP status code.
+	Detail   string // Human-readable explanation of this occurrence.
+	Instance string // URI reference identifying this occurrence.
+
+	// Extensions holds additional members, they cannot override the
+	// standard ones.
+	Extensions map[string]interface{}
+}
+
+// NewProblemResponse creates a new ProblemResponse with provided status and
+// detail, the title is the status text and the type is "about:blank".
+func NewProblemResponse(status int, detail string) ProblemResponse {
+	return ProblemResponse{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// GetBody returns the JSON encoding of the ProblemResponse, empty members are
+// omitted.
+func (p ProblemResponse) GetBody() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	standard := map[string]string{
+		"type":     p.Type,
+		"title":    p.Title,
+		"detail":   p.Detail,
+		"instance": p.Instance,
+	}
+	for key, value := range standard {
+		delete(members, key)
+		if value != "" {
+			members[key] = value
+		}
+	}
+	delete(members, "status")
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	return json.Marshal(members)
+}
+
+// GetCookie returns nil since no cookie is needed for this response.
+func (p ProblemResponse) GetCookie() *http.Cookie {
+	return nil
+}
+
+// GetHeaders returns the Content-Type header of the Problem Details.
+func (p ProblemResponse) GetHeaders() http.Header {
+	return http.Header{"Content-Type": {ProblemContentType}}
+}
+
+// GetStatusCode returns the status of the problem, so that it is used when
+// returned by the error-returning resource methods.
+func (p ProblemResponse) GetStatusCode() int {
+	return p.Status
+}
+
+// SetProblemDetails enables or disables the use of ProblemResponse for the
+// errors generated by gorest itself (e.g. 400 on form parsing failures, 405
+// and 500 on GetBody errors) and by the DefaultErrorMapper. When disabled,
+// the default, internal errors have an empty body and the DefaultErrorMapper
+// produces NAK SimpleResponse bodies.
+func (h *RestHandler) SetProblemDetails(enabled bool) {
+	h.problemDetails = &enabled
+}
+
+// writeError writes an error generated by gorest itself, with a Problem
+// Details body when enabled.
+func (h *RestHandler) writeError(w http.ResponseWriter, r *http.Request, code int, detail string) {
+	if !isEnabled(h.problemDetails) {
+		w.WriteHeader(code)
+		return
+	}
+
+	problem := NewProblemResponse(code, detail)
+	problem.Instance = r.URL.Path
+	body, _ := problem.GetBody()
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(code)
+	w.Write(body)
+}
@@ -0,0 +1,133 @@
+package gorest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestProblemResponseGetBody verifies the Problem Details encoding.
+func TestProblemResponseGetBody(t *testing.T) {
+	p := NewProblemResponse(http.StatusNotFound, "post 12 does not exist")
+	p.Instance = "/posts/12"
+	p.Extensions = map[string]interface{}{
+		"id":     12,
+		"status": "overridden",
+		"title":  "overridden",
+	}
+
+	body, err := p.GetBody()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	expected := `{"detail":"post 12 does not exist","id":12,"instance":"/posts/12","status":404,"title":"Not Found","type":"about:blank"}`
+	if string(body) != expected {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", expected, string(body))
+	}
+
+	body, _ = ProblemResponse{Title: "Only title"}.GetBody()
+	if string(body) != `{"title":"Only title"}` {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", `{"title":"Only title"}`, string(body))
+	}
+}
+
+// TestProblemResponseInterfaces verifies the Response and StatusCoder
+// implementations.
+func TestProblemResponseInterfaces(t *testing.T) {
+	var response Response = NewProblemResponse(http.StatusConflict, "")
+	if response.GetCookie() != nil {
+		t.Fatalf("Unexpected cookie: %+v.", response.GetCookie())
+	}
+	if contentType := response.GetHeaders().Get("Content-Type"); contentType != ProblemContentType {
+		t.Fatalf("Unexpected Content-Type. Expected: %s - Found: %s.", ProblemContentType, contentType)
+	}
+	if code := response.(StatusCoder).GetStatusCode(); code != http.StatusConflict {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusConflict, code)
+	}
+}
+
+// TestHandleRouteProblemDetails verifies that gorest internal errors use
+// Problem Details when enabled.
+func TestHandleRouteProblemDetails(t *testing.T) {
+	h := New()
+	h.SetProblemDetails(true)
+
+	// 405 Method Not Allowed.
+	w := httptest.NewRecorder()
+	h.handleRoute(NewRoute(testResourceWithGet{}, "/")).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/x", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusMethodNotAllowed, w.Code)
+	}
+	expected := `{"detail":"method POST is not supported","instance":"/x","status":405,"title":"Method Not Allowed","type":"about:blank"}`
+	if w.Body.String() != expected {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", expected, w.Body.String())
+	}
+	headers := w.Result().Header
+	if contentType := headers.Get("Content-Type"); contentType != ProblemContentType {
+		t.Fatalf("Unexpected Content-Type. Expected: %s - Found: %s.", ProblemContentType, contentType)
+	}
+	if allow := headers.Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Fatalf("Unexpected Allow header. Expected: %s - Found: %s.", "GET, HEAD, OPTIONS", allow)
+	}
+
+	// 400 Bad Request.
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Body = nil
+	w = httptest.NewRecorder()
+	h.handleRoute(NewRoute(testResourceWithPost{}, "/")).ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusBadRequest, w.Code)
+	}
+	expected = `{"detail":"invalid form data","instance":"/","status":400,"title":"Bad Request","type":"about:blank"}`
+	if w.Body.String() != expected {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", expected, w.Body.String())
+	}
+
+	// 500 Internal Server Error.
+	w = httptest.NewRecorder()
+	h.SetLogger(discardLogger)
+	h.handleRoute(NewRoute(testResourceWithGetAndResponse{
+		testResponse{bodyErr: fmt.Errorf("errorbody")},
+	}, "/")).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusInternalServerError, w.Code)
+	}
+	if contentType := w.Result().Header.Get("Content-Type"); contentType != ProblemContentType {
+		t.Fatalf("Unexpected Content-Type. Expected: %s - Found: %s.", ProblemContentType, contentType)
+	}
+}
+
+// TestErrorMapperProblemDetails verifies that the DefaultErrorMapper produces
+// Problem Details when enabled.
+func TestErrorMapperProblemDetails(t *testing.T) {
+	h := New()
+	h.SetProblemDetails(true)
+	h.RegisterRoute(NewRoute(testErrorResource{}, "/posts/{id}"))
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		return MapError(r, &ValidationError{Fields: []FieldError{{Pointer: "/name", Message: "is required"}}})
+	}), "/invalid"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/2", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusNotFound, w.Code)
+	}
+	expected := `{"detail":"not found","instance":"/posts/2","status":404,"title":"Not Found","type":"about:blank"}`
+	if w.Body.String() != expected {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", expected, w.Body.String())
+	}
+	if contentType := w.Result().Header.Get("Content-Type"); contentType != ProblemContentType {
+		t.Fatalf("Unexpected Content-Type. Expected: %s - Found: %s.", ProblemContentType, contentType)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/invalid", nil))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusUnprocessableEntity, w.Code)
+	}
+	expected = `{"detail":"validation failed","errors":[{"pointer":"/name","message":"is required"}],"instance":"/invalid","status":422,"title":"Unprocessable Entity","type":"about:blank"}`
+	if w.Body.String() != expected {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", expected, w.Body.String())
+	}
+}
@@ -0,0 +1,57 @@
+package gorest
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicHandler is invoked when a panic is recovered while serving a request,
+// it receives the route being served, the recovered value and the stack trace.
+type PanicHandler func(r *http.Request, route *Route, recovered interface{}, stack []byte)
+
+// SetPanicHandler sets the function invoked when a panic is recovered, if nil
+// the panic is logged.
+func (h *RestHandler) SetPanicHandler(handler PanicHandler) {
+	h.panicHandler = handler
+}
+
+// SetDevelopmentMode enables or disables the development mode, in which the
+// recovered panics are propagated after invoking the PanicHandler instead of
+// replying with 500 Internal Server Error.
+func (h *RestHandler) SetDevelopmentMode(enabled bool) {
+	h.developmentMode = &enabled
+}
+
+// recoverPanic handles a panic recovered while serving the request, replying
+// with 500 Internal Server Error if nothing has been written yet.
+func (h *RestHandler) recoverPanic(w *responseWriter, r *http.Request, route *Route, recovered interface{}) {
+	// ErrAbortHandler is used to deliberately abort the response.
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+
+	stack := debug.Stack()
+	if h.panicHandler != nil {
+		h.panicHandler(r, route, recovered, stack)
+	} else {
+		h.logf("gorest: panic serving %s %s (%s): %v\n%s", r.Method, r.URL.Path, route.GetPattern(), recovered, stack)
+	}
+
+	if isEnabled(h.developmentMode) {
+		panic(recovered)
+	}
+	if w.wroteHeader {
+		return
+	}
+
+	code, response := errorResponse(withHandler(r, h), http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+	body, _ := response.GetBody()
+	for key, values := range response.GetHeaders() {
+		w.Header()[key] = values
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	}
+	w.WriteHeader(code)
+	w.Write(body)
+}
@@ -0,0 +1,157 @@
+package gorest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// panickingResource panics while serving GET requests.
+var panickingResource = testResourceFunc(func(r *http.Request) (int, Response) {
+	panic("something went wrong")
+})
+
+// TestHandleRoutePanicRecovery verifies that panics are recovered replying
+// with 500 Internal Server Error and invoking the PanicHandler.
+func TestHandleRoutePanicRecovery(t *testing.T) {
+	var (
+		panicRoute *Route
+		recovered  interface{}
+		stack      []byte
+	)
+
+	h := New()
+	h.SetPanicHandler(func(r *http.Request, route *Route, value interface{}, s []byte) {
+		panicRoute, recovered, stack = route, value, s
+	})
+	h.RegisterRoute(NewRoute(panickingResource, "/panic"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusInternalServerError, w.Code)
+	}
+	if w.Body.String() != `{"status":"NAK","message":"Internal Server Error"}` {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", `{"status":"NAK","message":"Internal Server Error"}`, w.Body.String())
+	}
+	if panicRoute == nil || panicRoute.GetPattern() != "/panic" {
+		t.Fatalf("Unexpected route: %+v.", panicRoute)
+	}
+	if recovered != "something went wrong" {
+		t.Fatalf("Unexpected recovered value. Expected: %s - Found: %v.", "something went wrong", recovered)
+	}
+	if !strings.Contains(string(stack), "recovery_test.go") {
+		t.Fatalf("The stack trace should contain the panic location. Found: %s.", string(stack))
+	}
+
+	// The problem details are used when enabled.
+	h.SetProblemDetails(true)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusInternalServerError, w.Code)
+	}
+	if contentType := w.Result().Header.Get("Content-Type"); contentType != ProblemContentType {
+		t.Fatalf("Unexpected Content-Type. Expected: %s - Found: %s.", ProblemContentType, contentType)
+	}
+}
+
+// TestHandleRoutePanicOutsideResource verifies that panics raised outside the
+// resource, e.g. by the returned Response, are recovered too.
+func TestHandleRoutePanicOutsideResource(t *testing.T) {
+	h := New()
+	h.SetLogger(discardLogger)
+	h.Use(func(next Handler) Handler {
+		return func(r *http.Request) (int, Response) {
+			code, response := next(r)
+			return code, panickingResponse{response}
+		}
+	})
+	h.RegisterRoute(NewRoute(testResourceWithGet{}, "/"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusInternalServerError, w.Code)
+	}
+}
+
+// TestHandleRoutePanicDevelopmentMode verifies that panics are propagated in
+// development mode.
+func TestHandleRoutePanicDevelopmentMode(t *testing.T) {
+	invoked := false
+	h := New()
+	h.SetDevelopmentMode(true)
+	h.SetPanicHandler(func(*http.Request, *Route, interface{}, []byte) {
+		invoked = true
+	})
+	h.RegisterRoute(NewRoute(panickingResource, "/panic"))
+
+	defer func() {
+		if recovered := recover(); recovered != "something went wrong" {
+			t.Fatalf("Unexpected recovered value. Expected: %s - Found: %v.", "something went wrong", recovered)
+		}
+		if !invoked {
+			t.Fatalf("The panic handler should have been invoked.")
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	t.Fatalf("The panic should have been propagated.")
+}
+
+// TestHandleRoutePanicAbortHandler verifies that http.ErrAbortHandler is
+// always propagated.
+func TestHandleRoutePanicAbortHandler(t *testing.T) {
+	h := New()
+	h.SetPanicHandler(func(*http.Request, *Route, interface{}, []byte) {
+		t.Fatalf("The panic handler should not be invoked.")
+	})
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		panic(http.ErrAbortHandler)
+	}), "/abort"))
+
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Fatalf("Unexpected recovered value. Expected: %v - Found: %v.", http.ErrAbortHandler, recovered)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+}
+
+// panickingResponse is a Response panicking when its headers are requested.
+type panickingResponse struct {
+	Response
+}
+
+func (panickingResponse) GetBody() ([]byte, error) {
+	return nil, nil
+}
+
+func (panickingResponse) GetCookie() *http.Cookie {
+	return nil
+}
+
+func (panickingResponse) GetHeaders() http.Header {
+	panic("headers")
+}
+
+// TestRecoverPanicAfterWrite verifies that the response is left untouched
+// when the panic happens after the status line has been written.
+func TestRecoverPanicAfterWrite(t *testing.T) {
+	h := New()
+	h.SetLogger(discardLogger)
+
+	recorder := httptest.NewRecorder()
+	w := &responseWriter{ResponseWriter: recorder}
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("partial"))
+
+	h.recoverPanic(w, httptest.NewRequest(http.MethodGet, "/", nil), NewRoute(nil, "/"), "late panic")
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusAccepted, recorder.Code)
+	}
+	if recorder.Body.String() != "partial" {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", "partial", recorder.Body.String())
+	}
+}
@@ -0,0 +1,67 @@
+package gorest
+
+import (
+	"context"
+	"net/http"
+)
+
+// principalContextKey is the context key used to store the authenticated
+// principal of a request.
+type principalContextKey struct{}
+
+// payloadContextKey is the context key used to store the decoded body of a
+// request.
+type payloadContextKey struct{}
+
+// Request wraps the *http.Request being served together with the values set
+// by gorest and by the middlewares, it is provided to the context-aware
+// Resource methods.
+type Request struct {
+	*http.Request
+	Params    map[string]string // Path parameters matched by the router.
+	Principal interface{}       // Authenticated principal, see WithPrincipal.
+	Payload   interface{}       // Decoded request body, see WithPayload.
+}
+
+// newRequest wraps the provided request collecting the values stored in its
+// context.
+func newRequest(r *http.Request) *Request {
+	return &Request{
+		Request:   r,
+		Params:    PathParams(r),
+		Principal: GetPrincipal(r),
+		Payload:   GetPayload(r),
+	}
+}
+
+// WithPrincipal returns a copy of the request carrying the authenticated
+// principal, it is meant to be used by authentication middlewares before
+// invoking the next Handler.
+func WithPrincipal(r *http.Request, principal interface{}) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), principalContextKey{}, principal))
+}
+
+// GetPrincipal returns the authenticated principal of the request, or nil
+// if none has been set.
+func GetPrincipal(r *http.Request) interface{} {
+	return r.Context().Value(principalContextKey{})
+}
+
+// WithPayload returns a copy of the request carrying its decoded body.
+func WithPayload(r *http.Request, payload interface{}) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), payloadContextKey{}, payload))
+}
+
+// GetPayload returns the decoded body of the request, or nil if the body
+// has not been decoded.
+func GetPayload(r *http.Request) interface{} {
+	return r.Context().Value(payloadContextKey{})
+}
+
+// contextHandler adapts a context-aware Resource method to a Handler, the
+// context is the request one and is cancelled when the client disconnects.
+func contextHandler(fn func(context.Context, *Request) (int, Response)) Handler {
+	return func(r *http.Request) (int, Response) {
+		return fn(r.Context(), newRequest(r))
+	}
+}
@@ -0,0 +1,158 @@
+package gorest
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRequestValues verifies that the Request wrapper collects the values
+// stored in the request context.
+func TestRequestValues(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = withParams(r, map[string]string{"id": "1"})
+	r = WithPrincipal(r, "fred")
+	r = WithPayload(r, []int{1, 2})
+
+	req := newRequest(r)
+	if req.Request != r {
+		t.Fatalf("Unexpected wrapped request.")
+	}
+	if req.Params["id"] != "1" {
+		t.Fatalf("Unexpected id param. Expected: %s - Found: %s.", "1", req.Params["id"])
+	}
+	if req.Principal != "fred" {
+		t.Fatalf("Unexpected principal. Expected: %s - Found: %v.", "fred", req.Principal)
+	}
+	if payload, ok := req.Payload.([]int); !ok || len(payload) != 2 {
+		t.Fatalf("Unexpected payload: %+v.", req.Payload)
+	}
+
+	req = newRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+	if req.Principal != nil || req.Payload != nil || len(req.Params) != 0 {
+		t.Fatalf("Unexpected request values: %+v.", req)
+	}
+}
+
+// TestContextResource verifies that the context-aware methods are preferred
+// and receive the request values.
+func TestContextResource(t *testing.T) {
+	res := &testContextResource{}
+	h := New()
+	h.Use(func(next Handler) Handler {
+		return func(r *http.Request) (int, Response) {
+			return next(WithPrincipal(r, "fred"))
+		}
+	})
+	h.RegisterRoute(NewRoute(res, "/posts/{id}"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/12", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if res.called != "GetContext" {
+		t.Fatalf("Unexpected method called. Expected: %s - Found: %s.", "GetContext", res.called)
+	}
+	if res.request.Params["id"] != "12" {
+		t.Fatalf("Unexpected id param. Expected: %s - Found: %s.", "12", res.request.Params["id"])
+	}
+	if res.request.Principal != "fred" {
+		t.Fatalf("Unexpected principal. Expected: %s - Found: %v.", "fred", res.request.Principal)
+	}
+	if res.ctx == nil || res.ctx != res.request.Context() {
+		t.Fatalf("The context should be the request one.")
+	}
+
+	// HEAD is derived from the context-aware GET too.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/posts/12", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+
+	// The other methods are served by the context-aware variants as well.
+	others := map[string]string{
+		http.MethodPost:    "PostContext",
+		http.MethodPut:     "PutContext",
+		http.MethodPatch:   "PatchContext",
+		http.MethodDelete:  "DeleteContext",
+		http.MethodOptions: "OptionsContext",
+	}
+	for method, expected := range others {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/posts/12", nil))
+		if res.called != expected {
+			t.Fatalf("Unexpected method called. Expected: %s - Found: %s.", expected, res.called)
+		}
+	}
+
+	expected := "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS"
+	if found := strings.Join(supportedMethods(res), ", "); found != expected {
+		t.Fatalf("Unexpected supported methods. Expected: %s - Found: %s.", expected, found)
+	}
+}
+
+// TestHandleRouteClientDisconnect verifies that client disconnections are
+// reported in the logs.
+func TestHandleRouteClientDisconnect(t *testing.T) {
+	var buffer bytes.Buffer
+	h := New()
+	h.SetLogger(log.New(&buffer, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	route := NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		cancel()
+		return http.StatusOK, nil
+	}), "/")
+
+	req := httptest.NewRequest(http.MethodGet, "/disconnect", nil).WithContext(ctx)
+	h.handleRoute(route).ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.Contains(buffer.String(), "client disconnected while serving GET /disconnect") {
+		t.Fatalf("Unexpected log. Found: %s.", buffer.String())
+	}
+}
+
+// testContextResource records the context-aware method invocations.
+type testContextResource struct {
+	called  string
+	ctx     context.Context
+	request *Request
+}
+
+func (t *testContextResource) record(method string, ctx context.Context, r *Request) (int, Response) {
+	t.called, t.ctx, t.request = method, ctx, r
+	return http.StatusOK, nil
+}
+
+func (t *testContextResource) Get(r *http.Request) (int, Response) {
+	t.called = "Get"
+	return http.StatusOK, nil
+}
+
+func (t *testContextResource) GetContext(ctx context.Context, r *Request) (int, Response) {
+	return t.record("GetContext", ctx, r)
+}
+
+func (t *testContextResource) PostContext(ctx context.Context, r *Request) (int, Response) {
+	return t.record("PostContext", ctx, r)
+}
+
+func (t *testContextResource) PutContext(ctx context.Context, r *Request) (int, Response) {
+	return t.record("PutContext", ctx, r)
+}
+
+func (t *testContextResource) PatchContext(ctx context.Context, r *Request) (int, Response) {
+	return t.record("PatchContext", ctx, r)
+}
+
+func (t *testContextResource) DeleteContext(ctx context.Context, r *Request) (int, Response) {
+	return t.record("DeleteContext", ctx, r)
+}
+
+func (t *testContextResource) OptionsContext(ctx context.Context, r *Request) (int, Response) {
+	return t.record("OptionsContext", ctx, r)
+}
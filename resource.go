@@ -1,6 +1,7 @@
 package gorest
 
 import (
+	"context"
 	"net/http"
 )
 
@@ -33,6 +34,8 @@ type DeleteSupported interface {
 
 // HeadSupported is the interface that provides the Head
 // method a resource must support to receive HTTP HEADs.
+// Resources not implementing it but supporting GET receive HEADs through
+// their Get method, with the response body discarded.
 type HeadSupported interface {
 	Head(*http.Request) (int, Response)
 }
@@ -42,3 +45,206 @@ type HeadSupported interface {
 type PatchSupported interface {
 	Patch(*http.Request) (int, Response)
 }
+
+// OptionsSupported is the interface that provides the Options
+// method a resource must support to handle HTTP OPTIONS on its own,
+// otherwise gorest replies automatically listing the supported methods.
+type OptionsSupported interface {
+	Options(*http.Request) (int, Response)
+}
+
+// GetContextSupported is the interface that provides the context-aware
+// GetContext method, preferred to Get when both are implemented.
+type GetContextSupported interface {
+	GetContext(context.Context, *Request) (int, Response)
+}
+
+// PostContextSupported is the interface that provides the context-aware
+// PostContext method, preferred to Post when both are implemented.
+type PostContextSupported interface {
+	PostContext(context.Context, *Request) (int, Response)
+}
+
+// PutContextSupported is the interface that provides the context-aware
+// PutContext method, preferred to Put when both are implemented.
+type PutContextSupported interface {
+	PutContext(context.Context, *Request) (int, Response)
+}
+
+// DeleteContextSupported is the interface that provides the context-aware
+// DeleteContext method, preferred to Delete when both are implemented.
+type DeleteContextSupported interface {
+	DeleteContext(context.Context, *Request) (int, Response)
+}
+
+// HeadContextSupported is the interface that provides the context-aware
+// HeadContext method, preferred to Head when both are implemented.
+type HeadContextSupported interface {
+	HeadContext(context.Context, *Request) (int, Response)
+}
+
+// PatchContextSupported is the interface that provides the context-aware
+// PatchContext method, preferred to Patch when both are implemented.
+type PatchContextSupported interface {
+	PatchContext(context.Context, *Request) (int, Response)
+}
+
+// OptionsContextSupported is the interface that provides the context-aware
+// OptionsContext method, preferred to Options when both are implemented.
+type OptionsContextSupported interface {
+	OptionsContext(context.Context, *Request) (int, Response)
+}
+
+// GetErrSupported is the interface that provides the error-returning
+// GetErr method, errors are converted by the ErrorMapper of the handler.
+type GetErrSupported interface {
+	GetErr(*http.Request) (Response, error)
+}
+
+// PostErrSupported is the interface that provides the error-returning
+// PostErr method, errors are converted by the ErrorMapper of the handler.
+type PostErrSupported interface {
+	PostErr(*http.Request) (Response, error)
+}
+
+// PutErrSupported is the interface that provides the error-returning
+// PutErr method, errors are converted by the ErrorMapper of the handler.
+type PutErrSupported interface {
+	PutErr(*http.Request) (Response, error)
+}
+
+// DeleteErrSupported is the interface that provides the error-returning
+// DeleteErr method, errors are converted by the ErrorMapper of the handler.
+type DeleteErrSupported interface {
+	DeleteErr(*http.Request) (Response, error)
+}
+
+// HeadErrSupported is the interface that provides the error-returning
+// HeadErr method, errors are converted by the ErrorMapper of the handler.
+type HeadErrSupported interface {
+	HeadErr(*http.Request) (Response, error)
+}
+
+// PatchErrSupported is the interface that provides the error-returning
+// PatchErr method, errors are converted by the ErrorMapper of the handler.
+type PatchErrSupported interface {
+	PatchErr(*http.Request) (Response, error)
+}
+
+// OptionsErrSupported is the interface that provides the error-returning
+// OptionsErr method, errors are converted by the ErrorMapper of the handler.
+type OptionsErrSupported interface {
+	OptionsErr(*http.Request) (Response, error)
+}
+
+// methods lists the HTTP methods a resource can support, in the order they
+// are advertised.
+var methods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// supportedMethods returns the HTTP methods supported by the resource,
+// OPTIONS is always supported and HEAD is derived from GET when needed.
+func supportedMethods(r Resource) []string {
+	var supported []string
+	for _, method := range methods {
+		if resourceHandler(handlerMethod(method, r), r) != nil {
+			supported = append(supported, method)
+		}
+	}
+	return append(supported, http.MethodOptions)
+}
+
+// handlerMethod returns the method of the Resource handler serving requests
+// with provided method, that is GET for HEAD requests derived from GET.
+func handlerMethod(requestMethod string, r Resource) string {
+	if requestMethod == http.MethodHead && resourceHandler(http.MethodHead, r) == nil &&
+		resourceHandler(http.MethodGet, r) != nil {
+		return http.MethodGet
+	}
+	return requestMethod
+}
+
+// resourceHandler returns the Resource handler for provided method, the
+// context-aware variants are preferred when implemented, followed by the
+// error-returning ones.
+func resourceHandler(method string, r Resource) Handler {
+	switch method {
+	case http.MethodGet:
+		if res, ok := r.(GetContextSupported); ok {
+			return contextHandler(res.GetContext)
+		}
+		if res, ok := r.(GetErrSupported); ok {
+			return errorHandler(res.GetErr)
+		}
+		if res, ok := r.(GetSupported); ok {
+			return res.Get
+		}
+	case http.MethodPost:
+		if res, ok := r.(PostContextSupported); ok {
+			return contextHandler(res.PostContext)
+		}
+		if res, ok := r.(PostErrSupported); ok {
+			return errorHandler(res.PostErr)
+		}
+		if res, ok := r.(PostSupported); ok {
+			return res.Post
+		}
+	case http.MethodPut:
+		if res, ok := r.(PutContextSupported); ok {
+			return contextHandler(res.PutContext)
+		}
+		if res, ok := r.(PutErrSupported); ok {
+			return errorHandler(res.PutErr)
+		}
+		if res, ok := r.(PutSupported); ok {
+			return res.Put
+		}
+	case http.MethodDelete:
+		if res, ok := r.(DeleteContextSupported); ok {
+			return contextHandler(res.DeleteContext)
+		}
+		if res, ok := r.(DeleteErrSupported); ok {
+			return errorHandler(res.DeleteErr)
+		}
+		if res, ok := r.(DeleteSupported); ok {
+			return res.Delete
+		}
+	case http.MethodHead:
+		if res, ok := r.(HeadContextSupported); ok {
+			return contextHandler(res.HeadContext)
+		}
+		if res, ok := r.(HeadErrSupported); ok {
+			return errorHandler(res.HeadErr)
+		}
+		if res, ok := r.(HeadSupported); ok {
+			return res.Head
+		}
+	case http.MethodPatch:
+		if res, ok := r.(PatchContextSupported); ok {
+			return contextHandler(res.PatchContext)
+		}
+		if res, ok := r.(PatchErrSupported); ok {
+			return errorHandler(res.PatchErr)
+		}
+		if res, ok := r.(PatchSupported); ok {
+			return res.Patch
+		}
+	case http.MethodOptions:
+		if res, ok := r.(OptionsContextSupported); ok {
+			return contextHandler(res.OptionsContext)
+		}
+		if res, ok := r.(OptionsErrSupported); ok {
+			return errorHandler(res.OptionsErr)
+		}
+		if res, ok := r.(OptionsSupported); ok {
+			return res.Options
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package gorest
 import (
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -228,6 +229,35 @@ func TestResourcePatchSupport(t *testing.T) {
 	}
 }
 
+// TestSupportedMethods verifies the supported methods detection.
+func TestSupportedMethods(t *testing.T) {
+	type resourceWithAll struct {
+		testResourceWithGet
+		testResourceWithHead
+		testResourceWithPost
+		testResourceWithPut
+		testResourceWithPatch
+		testResourceWithDelete
+	}
+
+	tests := []struct {
+		resource Resource
+		expected string
+	}{
+		{struct{}{}, "OPTIONS"},
+		{testResourceWithGet{}, "GET, HEAD, OPTIONS"},
+		{testResourceWithHead{}, "HEAD, OPTIONS"},
+		{testResourceWithOptions{}, "OPTIONS"},
+		{testErrorResource{}, "GET, HEAD, POST, OPTIONS"},
+		{resourceWithAll{}, "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS"},
+	}
+	for _, test := range tests {
+		if found := strings.Join(supportedMethods(test.resource), ", "); found != test.expected {
+			t.Fatalf("Unexpected methods for %s. Expected: %s - Found: %s.", reflect.TypeOf(test.resource).String(), test.expected, found)
+		}
+	}
+}
+
 //
 // Structures for test
 //
@@ -275,3 +305,16 @@ type testResourceWithPatch struct{}
 func (testResourceWithPatch) Patch(r *http.Request) (int, Response) {
 	return 200, nil
 }
+
+type testResourceWithOptions struct{}
+
+func (testResourceWithOptions) Options(r *http.Request) (int, Response) {
+	return 200, nil
+}
+
+// testResourceFunc is a resource serving GET requests with the wrapped function.
+type testResourceFunc func(r *http.Request) (int, Response)
+
+func (f testResourceFunc) Get(r *http.Request) (int, Response) {
+	return f(r)
+}
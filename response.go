@@ -1,7 +1,9 @@
 package gorest
 
 import (
+	"encoding/json"
 	"net/http"
+	"time"
 )
 
 // Response is the response structure that must be implemented and returned to
@@ -11,3 +13,109 @@ type Response interface {
 	GetCookie() *http.Cookie
 	GetHeaders() http.Header
 }
+
+// CookiesResponse is the interface implemented by the responses setting
+// more than one cookie, when implemented GetCookie is not used.
+type CookiesResponse interface {
+	GetCookies() []*http.Cookie
+}
+
+// responseCookies returns the cookies to be set for the response.
+func responseCookies(response Response) []*http.Cookie {
+	if res, ok := response.(CookiesResponse); ok {
+		return res.GetCookies()
+	}
+	if cookie := response.GetCookie(); cookie != nil {
+		return []*http.Cookie{cookie}
+	}
+	return nil
+}
+
+// joinCookies returns the cookie, if not nil, followed by the other cookies.
+func joinCookies(cookie *http.Cookie, cookies []*http.Cookie) []*http.Cookie {
+	if cookie == nil {
+		return cookies
+	}
+	return append([]*http.Cookie{cookie}, cookies...)
+}
+
+// expiredCookie returns a cookie instructing the client to delete the cookie
+// with provided name and path.
+func expiredCookie(name, path string) *http.Cookie {
+	return &http.Cookie{Name: name, Path: path, MaxAge: -1, Expires: time.Unix(0, 0)}
+}
+
+// responseHeaders implements the cookies and headers methods shared by the
+// responses, it is embedded in each of them.
+type responseHeaders struct {
+	cookie  *http.Cookie
+	cookies []*http.Cookie
+	headers http.Header
+}
+
+// SetCookie can be used to set a custom cookie that will be set in the
+// HTTP response writer.
+func (r *responseHeaders) SetCookie(cookie *http.Cookie) {
+	r.cookie = cookie
+}
+
+// GetCookie will be used by gorest core to retrieve the cookie to be sent in
+// the HTTP response writer.
+func (r *responseHeaders) GetCookie() *http.Cookie {
+	return r.cookie
+}
+
+// AddCookie can be used to add a cookie to the ones that will be set in the
+// HTTP response writer.
+func (r *responseHeaders) AddCookie(cookie *http.Cookie) {
+	r.cookies = append(r.cookies, cookie)
+}
+
+// DeleteCookie can be used to instruct the client to delete the cookie with
+// provided name and path, by setting it as expired.
+func (r *responseHeaders) DeleteCookie(name, path string) {
+	r.AddCookie(expiredCookie(name, path))
+}
+
+// GetCookies will be used by gorest core to retrieve all the cookies to be
+// sent in the HTTP response writer, starting with the one set by SetCookie.
+func (r *responseHeaders) GetCookies() []*http.Cookie {
+	return joinCookies(r.cookie, r.cookies)
+}
+
+// SetHeaders can be used to set a custom set of headers that will be set in
+// the HTTP response writer.
+func (r *responseHeaders) SetHeaders(headers http.Header) {
+	r.headers = headers
+}
+
+// AddHeader can be used to add a value to the header, keeping the existing
+// ones, which will be sent separately in the HTTP response writer.
+func (r *responseHeaders) AddHeader(key, value string) {
+	if r.headers == nil {
+		r.headers = make(http.Header)
+	}
+	r.headers.Add(key, value)
+}
+
+// GetHeaders will be used by gorest core to retrieve the headers to be sent in
+// the HTTP response writer.
+func (r *responseHeaders) GetHeaders() http.Header {
+	return r.headers
+}
+
+// ContentTyper is the interface implemented by the responses declaring the
+// Content-Type of their body, when it is not declared, nor set among the
+// headers, gorest detects it from the body.
+type ContentTyper interface {
+	GetContentType() string
+}
+
+// detectContentType returns the Content-Type of a body whose media type has
+// not been declared: JSON is recognized, otherwise the type is sniffed.
+func detectContentType(body []byte) string {
+	if json.Valid(body) {
+		return "application/json; charset=UTF-8"
+	}
+	return http.DetectContentType(body)
+}
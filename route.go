@@ -2,8 +2,18 @@ package gorest
 
 // Route defines a route pattern for a Resource.
 type Route struct {
-	resource Resource
-	pattern  string
+	resource    Resource
+	pattern     string
+	methods     []string
+	middleware  []Middleware
+	upload      *UploadConfig
+	cachePolicy *CachePolicy
+
+	noCompression        bool // Whether the response compression is disabled.
+	requirePreconditions bool // Whether unsafe requests must be conditional.
+
+	owner    *RestHandler   // Handler whose settings serve a mounted route.
+	handlers []*RestHandler // Handlers the route has been registered into.
 }
 
 // NewRoute defines a New route object.
@@ -11,6 +21,7 @@ func NewRoute(resource Resource, pattern string) *Route {
 	return &Route{
 		resource: resource,
 		pattern:  pattern,
+		methods:  supportedMethods(resource),
 	}
 }
 
@@ -23,3 +34,48 @@ func (r *Route) GetPattern() string {
 func (r *Route) GetResource() Resource {
 	return r.resource
 }
+
+// GetMethods returns the HTTP methods supported by the Resource, computed
+// when the route is created.
+func (r *Route) GetMethods() []string {
+	return r.methods
+}
+
+// Use appends provided middlewares to the ones wrapping the Resource handler
+// of the route, they run after the RestHandler middlewares.
+// The route itself is returned to allow chaining calls.
+func (r *Route) Use(middleware ...Middleware) *Route {
+	r.middleware = append(r.middleware, middleware...)
+	r.resetRouters()
+	return r
+}
+
+// GetMiddleware returns the middlewares specific to the route.
+func (r *Route) GetMiddleware() []Middleware {
+	return r.middleware
+}
+
+// SetUploadConfig sets the limits and the storage of the uploads accepted by
+// the route, see ReadUploads. The MaxTotalSize limit applies to any request
+// body, including the URL-encoded forms parsed by gorest.
+// The route itself is returned to allow chaining calls.
+func (r *Route) SetUploadConfig(config UploadConfig) *Route {
+	r.upload = &config
+	r.resetRouters()
+	return r
+}
+
+// GetUploadConfig returns the upload configuration of the route, nil if not
+// set.
+func (r *Route) GetUploadConfig() *UploadConfig {
+	return r.upload
+}
+
+// resetRouters discards the native routers of the handlers the route has
+// been registered into, so that the changes made to the route once the
+// requests are being served are applied.
+func (r *Route) resetRouters() {
+	for _, h := range r.handlers {
+		h.resetRouter()
+	}
+}
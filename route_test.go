@@ -34,3 +34,28 @@ func TestRouteGetResource(t *testing.T) {
 		t.Fatalf("Unexpected resource content. Expected: %s - Found: %s.", "a_value", convertedResource.A)
 	}
 }
+
+// TestRouteUse verifies the Route Use behaviour.
+func TestRouteUse(t *testing.T) {
+	noop := func(next Handler) Handler { return next }
+
+	r := NewRoute(nil, "/the/pattern")
+	if len(r.GetMiddleware()) != 0 {
+		t.Fatalf("Unexpected middlewares found: %d.", len(r.GetMiddleware()))
+	}
+	if r.Use(noop, noop).Use(noop) != r {
+		t.Fatalf("Use should return the route itself.")
+	}
+	if len(r.GetMiddleware()) != 3 {
+		t.Fatalf("Unexpected middlewares count. Expected: %d - Found: %d.", 3, len(r.GetMiddleware()))
+	}
+}
+
+// TestRouteGetMethods verifies the Route GetMethods behaviour.
+func TestRouteGetMethods(t *testing.T) {
+	r := NewRoute(testResourceWithPut{}, "/the/pattern")
+	methods := r.GetMethods()
+	if len(methods) != 2 || methods[0] != "PUT" || methods[1] != "OPTIONS" {
+		t.Fatalf("Unexpected methods. Expected: %v - Found: %v.", []string{"PUT", "OPTIONS"}, methods)
+	}
+}
@@ -0,0 +1,198 @@
+package gorest
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// router is a path segment trie used to dispatch requests to the Resource
+// registered for the matching Route pattern.
+//
+// Patterns are split on "/" and each segment is either static or a named
+// parameter written as "{name}" or "{name:constraint}", parameters must span
+// the whole segment. The constraint is either a regular expression or one of
+// the named constraints "int", "uuid" and "slug"; when a segment does not
+// satisfy it the parameter does not match, resulting in a 404 Not Found.
+// Static segments always take precedence over parameters, and constrained
+// parameters over unconstrained ones.
+type router struct {
+	root *node
+}
+
+// node is a single segment of the router trie.
+type node struct {
+	children map[string]*node // Static segment children.
+	params   []*node          // Parameter segment children.
+	name     string           // Parameter name for parameter nodes.
+	pattern  string           // Parameter constraint for parameter nodes.
+	re       *regexp.Regexp   // Compiled parameter constraint, if any.
+	route    *Route           // Route terminating at this node, if any.
+	handler  http.HandlerFunc // Handler serving the terminating route.
+}
+
+// newRouter creates a new empty router.
+func newRouter() *router {
+	return &router{root: &node{}}
+}
+
+// add registers the handler for provided route; when two routes share the
+// same pattern the first registered one wins.
+func (rt *router) add(route *Route, handler http.HandlerFunc) {
+	n := rt.root
+	for _, segment := range splitPath(route.GetPattern()) {
+		if name, constraint, ok := parseParamSegment(segment); ok {
+			n = n.paramChild(name, constraint)
+			continue
+		}
+
+		if n.children == nil {
+			n.children = make(map[string]*node)
+		}
+		child, ok := n.children[segment]
+		if !ok {
+			child = &node{}
+			n.children[segment] = child
+		}
+		n = child
+	}
+
+	if n.route == nil {
+		n.route = route
+		n.handler = handler
+	}
+}
+
+// paramChild returns the parameter child with provided name and constraint,
+// creating it if needed.
+func (n *node) paramChild(name, constraint string) *node {
+	for _, child := range n.params {
+		if child.name == name && child.pattern == constraint {
+			return child
+		}
+	}
+
+	child := &node{name: name, pattern: constraint}
+	if constraint != "" {
+		child.re = regexp.MustCompile("^(?:" + constraint + ")$")
+	}
+
+	// Keep constrained parameters before unconstrained ones.
+	i := len(n.params)
+	for i > 0 && constraint != "" && n.params[i-1].re == nil {
+		i--
+	}
+	n.params = append(n.params, nil)
+	copy(n.params[i+1:], n.params[i:])
+	n.params[i] = child
+	return child
+}
+
+// lookup searches the node matching the provided path and returns it along
+// with the path parameters collected while walking the trie.
+func (rt *router) lookup(path string) (*node, map[string]string) {
+	params := make(map[string]string)
+	n := rt.root.match(splitPath(path), params)
+	if n == nil {
+		return nil, nil
+	}
+	return n, params
+}
+
+// match walks the trie with the remaining segments, backtracking from static
+// children to parameters when needed.
+func (n *node) match(segments []string, params map[string]string) *node {
+	if len(segments) == 0 {
+		if n.route == nil {
+			return nil
+		}
+		return n
+	}
+
+	segment, rest := segments[0], segments[1:]
+	if child, ok := n.children[segment]; ok {
+		if found := child.match(rest, params); found != nil {
+			return found
+		}
+	}
+
+	if segment == "" {
+		return nil
+	}
+	for _, param := range n.params {
+		if param.re != nil && !param.re.MatchString(segment) {
+			continue
+		}
+		if found := param.match(rest, params); found != nil {
+			params[param.name] = segment
+			return found
+		}
+	}
+	return nil
+}
+
+// ServeHTTP dispatches the request to the Route matching its path, making
+// RestHandler usable directly as an http.Handler.
+func (h *RestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt := h.getRouter()
+
+	n, params := rt.lookup(r.URL.Path)
+	if n == nil {
+		// Mimic the strict slash behaviour of the mux router by redirecting
+		// to the path with (or without) the trailing slash.
+		if target, ok := rt.toggleTrailingSlash(r.URL.Path); ok {
+			u := *r.URL
+			u.Path = target
+			http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(params) > 0 {
+		r = r.WithContext(context.WithValue(r.Context(), paramsContextKey{}, params))
+	}
+	n.handler(w, r)
+}
+
+// toggleTrailingSlash verifies whether the path obtained adding or removing
+// the trailing slash matches a route.
+func (rt *router) toggleTrailingSlash(path string) (string, bool) {
+	var target string
+	switch {
+	case path == "/":
+		return "", false
+	case strings.HasSuffix(path, "/"):
+		target = strings.TrimSuffix(path, "/")
+	default:
+		target = path + "/"
+	}
+
+	if n, _ := rt.lookup(target); n != nil {
+		return target, true
+	}
+	return "", false
+}
+
+// getRouter returns the native router, building it from the registered
+// routes if they changed since the last build.
+func (h *RestHandler) getRouter() *router {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.router == nil {
+		rt := newRouter()
+		for _, route := range h.GetRoutes() {
+			rt.add(route, h.handleRoute(route))
+		}
+		h.router = rt
+	}
+	return h.router
+}
+
+// splitPath splits a path or a pattern into its segments.
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
@@ -0,0 +1,181 @@
+package gorest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRouterLookup verifies that static and parameter segments are properly
+// matched by the router trie.
+func TestRouterLookup(t *testing.T) {
+	rt := newRouter()
+	routes := []*Route{
+		NewRoute(testResourceWithGet{}, "/"),
+		NewRoute(testResourceWithGet{}, "/posts"),
+		NewRoute(testResourceWithGet{}, "/posts/{id}"),
+		NewRoute(testResourceWithGet{}, "/posts/latest"),
+		NewRoute(testResourceWithGet{}, "/posts/{id}/comments/{comment}"),
+	}
+	for _, route := range routes {
+		rt.add(route, nil)
+	}
+
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/", "/", map[string]string{}},
+		{"/posts", "/posts", map[string]string{}},
+		{"/posts/12", "/posts/{id}", map[string]string{"id": "12"}},
+		{"/posts/latest", "/posts/latest", map[string]string{}},
+		{"/posts/12/comments/3", "/posts/{id}/comments/{comment}", map[string]string{"id": "12", "comment": "3"}},
+		{"/posts/12/comments", "", nil},
+		{"/unknown", "", nil},
+		{"/posts//comments/3", "", nil},
+	}
+
+	for _, test := range tests {
+		n, params := rt.lookup(test.path)
+		if test.pattern == "" {
+			if n != nil {
+				t.Fatalf("Unexpected match for path %s: %s.", test.path, n.route.GetPattern())
+			}
+			continue
+		}
+		if n == nil {
+			t.Fatalf("Unexpected nil match for path %s.", test.path)
+		}
+		if n.route.GetPattern() != test.pattern {
+			t.Fatalf("Unexpected pattern for path %s. Expected: %s - Found: %s.", test.path, test.pattern, n.route.GetPattern())
+		}
+		if len(params) != len(test.params) {
+			t.Fatalf("Unexpected params for path %s. Expected: %v - Found: %v.", test.path, test.params, params)
+		}
+		for key, value := range test.params {
+			if params[key] != value {
+				t.Fatalf("Unexpected param %s for path %s. Expected: %s - Found: %s.", key, test.path, value, params[key])
+			}
+		}
+	}
+}
+
+// TestRouterFirstRouteWins verifies that when two routes share the same
+// pattern the first registered one is used.
+func TestRouterFirstRouteWins(t *testing.T) {
+	rt := newRouter()
+	first := NewRoute(testResourceWithGet{}, "/a/{id}")
+	second := NewRoute(testResourceWithPost{}, "/a/{other}")
+	rt.add(first, nil)
+	rt.add(second, nil)
+
+	n, params := rt.lookup("/a/1")
+	if n == nil || n.route != first {
+		t.Fatalf("Unexpected route matched. Expected: %+v - Found: %+v.", first, n)
+	}
+	if params["id"] != "1" {
+		t.Fatalf("Unexpected id param. Expected: %s - Found: %s.", "1", params["id"])
+	}
+}
+
+// TestRestHandlerServeHTTP verifies that the RestHandler can be used directly
+// as an http.Handler.
+func TestRestHandlerServeHTTP(t *testing.T) {
+	h := New()
+	h.RegisterRoute(NewRoute(testResourceWithGetAndResponse{
+		testResponse{body: "testbody"},
+	}, "/posts/{id}"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if w.Body.String() != "testbody" {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", "testbody", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts/1", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/comments/1", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusNotFound, w.Code)
+	}
+}
+
+// TestRestHandlerServeHTTPTrailingSlash verifies that requests differing from
+// a route only by the trailing slash are redirected.
+func TestRestHandlerServeHTTPTrailingSlash(t *testing.T) {
+	h := New()
+	h.SetRoutes([]*Route{
+		NewRoute(testResourceWithGet{}, "/posts"),
+		NewRoute(testResourceWithGet{}, "/comments/"),
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/?page=2", nil))
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusMovedPermanently, w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/posts?page=2" {
+		t.Fatalf("Unexpected location. Expected: %s - Found: %s.", "/posts?page=2", location)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/comments", nil))
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusMovedPermanently, w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/comments/" {
+		t.Fatalf("Unexpected location. Expected: %s - Found: %s.", "/comments/", location)
+	}
+}
+
+// TestRestHandlerServeHTTPRoutesUpdate verifies that routes registered after
+// the first request are served too.
+func TestRestHandlerServeHTTPRoutesUpdate(t *testing.T) {
+	h := New()
+	h.RegisterRoute(NewRoute(testResourceWithGet{}, "/a"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/b", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusNotFound, w.Code)
+	}
+
+	h.RegisterRoute(NewRoute(testResourceWithGet{}, "/b"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/b", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+}
+
+// TestPathParams verifies that the path parameters are made available to
+// the resources served by the native router.
+func TestPathParams(t *testing.T) {
+	var params map[string]string
+	h := New()
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		params = PathParams(r)
+		return http.StatusOK, nil
+	}), "/users/{user}/posts/{post}"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/fred/posts/42", nil))
+	if params["user"] != "fred" {
+		t.Fatalf("Unexpected user param. Expected: %s - Found: %s.", "fred", params["user"])
+	}
+	if params["post"] != "42" {
+		t.Fatalf("Unexpected post param. Expected: %s - Found: %s.", "42", params["post"])
+	}
+
+	if params := PathParams(httptest.NewRequest(http.MethodGet, "/", nil)); len(params) != 0 {
+		t.Fatalf("Unexpected params. Expected empty map - Found: %v.", params)
+	}
+}
@@ -0,0 +1,203 @@
+package gorest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventStreamContentType is the media type of the Server-Sent Events streams.
+const EventStreamContentType = "text/event-stream"
+
+// Event is a Server-Sent Event.
+type Event struct {
+	ID    string        // Identifier used by the client to resume the stream.
+	Event string        // Event type, "message" if empty.
+	Data  string        // Event data, it can span multiple lines.
+	Retry time.Duration // Reconnection time the client should use, if set.
+}
+
+// WriteTo writes the event in the text/event-stream format.
+func (e Event) WriteTo(w io.Writer) (int64, error) {
+	var buffer bytes.Buffer
+	if e.ID != "" {
+		buffer.WriteString("id: " + eventField(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		buffer.WriteString("event: " + eventField(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		buffer.WriteString("retry: " + strconv.FormatInt(int64(e.Retry/time.Millisecond), 10) + "\n")
+	}
+	// CRLF, CR and LF are all line terminators in the event stream format.
+	data := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(e.Data)
+	for _, line := range strings.Split(data, "\n") {
+		buffer.WriteString("data: " + line + "\n")
+	}
+	buffer.WriteString("\n")
+	return buffer.WriteTo(w)
+}
+
+// eventField removes the line breaks from single line event fields.
+func eventField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// ReplayBuffer stores the published events allowing the clients to resume a
+// stream from the last event they received, identified by the Last-Event-ID
+// header. The events must be added by the publisher.
+type ReplayBuffer interface {
+	Add(event Event)
+	Since(lastEventID string) []Event
+}
+
+// MemoryReplayBuffer is a ReplayBuffer keeping the last events in memory.
+type MemoryReplayBuffer struct {
+	mu     sync.RWMutex
+	size   int
+	events []Event
+}
+
+// NewMemoryReplayBuffer creates a new MemoryReplayBuffer keeping up to size
+// events.
+func NewMemoryReplayBuffer(size int) *MemoryReplayBuffer {
+	return &MemoryReplayBuffer{size: size}
+}
+
+// Add stores the event, discarding the oldest one if the buffer is full.
+// Events without ID cannot be resumed from and are ignored.
+func (b *MemoryReplayBuffer) Add(event Event) {
+	if event.ID == "" || b.size <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, event)
+	if len(b.events) > b.size {
+		b.events = append([]Event(nil), b.events[len(b.events)-b.size:]...)
+	}
+}
+
+// Since returns the events stored after the one with provided ID, nil is
+// returned if the event is no longer, or has never been, stored.
+func (b *MemoryReplayBuffer) Since(lastEventID string) []Event {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for i, event := range b.events {
+		if event.ID == lastEventID {
+			return append([]Event(nil), b.events[i+1:]...)
+		}
+	}
+	return nil
+}
+
+// EventResponse is a Server-Sent Events response, the events received from
+// the channel are sent to the client until the channel is closed or the
+// request context is cancelled.
+type EventResponse struct {
+	responseHeaders
+	events    <-chan Event
+	heartbeat time.Duration
+	replay    ReplayBuffer
+}
+
+// NewEventResponse creates a new EventResponse sending the events received
+// from the channel.
+func NewEventResponse(events <-chan Event) *EventResponse {
+	return &EventResponse{events: events}
+}
+
+// SetHeartbeat can be used to set the interval of the comments sent to keep
+// the connection alive while no events are published, 0 disables them.
+func (r *EventResponse) SetHeartbeat(interval time.Duration) {
+	r.heartbeat = interval
+}
+
+// SetReplayBuffer can be used to set the buffer the missed events are sent
+// from when the client resumes the stream using the Last-Event-ID header.
+func (r *EventResponse) SetReplayBuffer(buffer ReplayBuffer) {
+	r.replay = buffer
+}
+
+// GetBody returns nil since the events are sent by gorest core as they are
+// received.
+func (r *EventResponse) GetBody() ([]byte, error) {
+	return nil, nil
+}
+
+// writeEvents writes the headers and sends the events of the response until
+// the channel is closed or the request context is cancelled.
+func (h *RestHandler) writeEvents(w *responseWriter, r *http.Request, code int, response *EventResponse) {
+	setResponseHeaders(w, response)
+	w.Header().Set("Content-Type", EventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Del("Content-Length")
+	// Disable the response buffering of proxies like nginx.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(code)
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Flush()
+
+	send := func(event Event) bool {
+		if _, err := event.WriteTo(w); err != nil {
+			h.streamError(r, err)
+			return false
+		}
+		w.Flush()
+		return true
+	}
+
+	// The replayed events may also be received from the channel, since the
+	// publisher adds them to the buffer as it sends them, they are skipped.
+	var replayed map[string]bool
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" && response.replay != nil {
+		for _, event := range response.replay.Since(lastEventID) {
+			if !send(event) {
+				return
+			}
+			if replayed == nil {
+				replayed = make(map[string]bool)
+			}
+			if event.ID != "" {
+				replayed[event.ID] = true
+			}
+		}
+	}
+
+	var heartbeat <-chan time.Time
+	if response.heartbeat > 0 {
+		ticker := time.NewTicker(response.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-response.events:
+			if !ok {
+				return
+			}
+			if replayed[event.ID] {
+				delete(replayed, event.ID)
+				continue
+			}
+			if !send(event) {
+				return
+			}
+		case <-heartbeat:
+			if _, err := io.WriteString(w, ":\n\n"); err != nil {
+				h.streamError(r, err)
+				return
+			}
+			w.Flush()
+		}
+	}
+}
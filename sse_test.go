@@ -0,0 +1,132 @@
+package gorest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEventWriteTo verifies the text/event-stream encoding of the events.
+func TestEventWriteTo(t *testing.T) {
+	var buffer bytes.Buffer
+	Event{ID: "1", Event: "update\n", Data: "a\r\nb", Retry: 2 * time.Second}.WriteTo(&buffer)
+
+	expected := "id: 1\nevent: update\nretry: 2000\ndata: a\ndata: b\n\n"
+	if buffer.String() != expected {
+		t.Fatalf("Unexpected event. Expected: %q - Found: %q.", expected, buffer.String())
+	}
+
+	// Lone carriage returns cannot be used to inject fields.
+	buffer.Reset()
+	Event{Data: "hi\revent: admin\rdata: pwned\r\n\nend"}.WriteTo(&buffer)
+	expected = "data: hi\ndata: event: admin\ndata: data: pwned\ndata: \ndata: end\n\n"
+	if buffer.String() != expected {
+		t.Fatalf("Unexpected event. Expected: %q - Found: %q.", expected, buffer.String())
+	}
+}
+
+// TestMemoryReplayBuffer verifies that the buffer keeps the last events.
+func TestMemoryReplayBuffer(t *testing.T) {
+	buffer := NewMemoryReplayBuffer(2)
+	for _, id := range []string{"1", "2", "", "3"} {
+		buffer.Add(Event{ID: id})
+	}
+
+	if events := buffer.Since("1"); events != nil {
+		t.Fatalf("Unexpected events. Expected: nil - Found: %v.", events)
+	}
+	events := buffer.Since("2")
+	if len(events) != 1 || events[0].ID != "3" {
+		t.Fatalf("Unexpected events. Expected: [3] - Found: %v.", events)
+	}
+}
+
+// TestEventResponse verifies that the events are sent, starting from the
+// replayed ones, until the channel is closed.
+func TestEventResponse(t *testing.T) {
+	replay := NewMemoryReplayBuffer(10)
+	replay.Add(Event{ID: "1", Data: "one"})
+	replay.Add(Event{ID: "2", Data: "two"})
+
+	h := New()
+	h.RegisterRoute(NewRoute(testStreamResource{func() Response {
+		// The replayed events received again from the channel are skipped.
+		events := make(chan Event, 2)
+		events <- Event{ID: "2", Data: "two"}
+		events <- Event{ID: "3", Data: "three"}
+		close(events)
+
+		response := NewEventResponse(events)
+		response.SetReplayBuffer(replay)
+		response.AddCookie(&http.Cookie{Name: "a", Value: "1"})
+		response.AddCookie(&http.Cookie{Name: "b", Value: "2"})
+		return response
+	}}, "/"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	res := w.Result()
+	if contentType := res.Header.Get("Content-Type"); contentType != EventStreamContentType {
+		t.Fatalf("Unexpected Content-Type. Expected: %s - Found: %s.", EventStreamContentType, contentType)
+	}
+	if cookies := res.Cookies(); len(cookies) != 2 {
+		t.Fatalf("Unexpected cookies. Expected: 2 - Found: %d.", len(cookies))
+	}
+	if cacheControl := res.Header.Get("Cache-Control"); cacheControl != "no-cache" {
+		t.Fatalf("Unexpected Cache-Control. Expected: no-cache - Found: %s.", cacheControl)
+	}
+	expected := "id: 2\ndata: two\n\nid: 3\ndata: three\n\n"
+	if body := w.Body.String(); body != expected {
+		t.Fatalf("Unexpected body. Expected: %q - Found: %q.", expected, body)
+	}
+	if !w.Flushed {
+		t.Fatalf("Events should have been flushed.")
+	}
+}
+
+// TestEventResponseHeartbeatAndCancel verifies that heartbeats are sent while
+// no events are published and that the stream stops when the request context
+// is cancelled.
+func TestEventResponseHeartbeatAndCancel(t *testing.T) {
+	h := New()
+	h.RegisterRoute(NewRoute(testStreamResource{func() Response {
+		response := NewEventResponse(make(chan Event))
+		response.SetHeartbeat(10 * time.Millisecond)
+		return response
+	}}, "/"))
+
+	served := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r)
+		close(served)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	defer res.Body.Close()
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, ":") {
+		t.Fatalf("Unexpected heartbeat. Expected: ':' - Found: %q (%v).", line, err)
+	}
+
+	cancel()
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		t.Fatalf("Stream should have stopped after the request cancellation.")
+	}
+}
@@ -3,7 +3,7 @@ package gorest
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"mime"
 )
 
 // StandardResponse is the response that usually will be used for response
@@ -11,9 +11,11 @@ import (
 // and implements the Response interface, if you do not need specific
 // functionalities in the responses then this is the object you want to use.
 type StandardResponse struct {
-	body    []byte
-	cookie  *http.Cookie
-	headers http.Header
+	responseHeaders
+	responseCachePolicy
+	code        int
+	body        []byte
+	contentType string
 }
 
 // NewStandardResponse creates a new empty Response.
@@ -44,26 +46,30 @@ func (r *StandardResponse) GetBody() ([]byte, error) {
 	return r.body, nil
 }
 
-// SetCookie can be used to set a custom cookie that will be set in the
-// HTTP response writer.
-func (r *StandardResponse) SetCookie(cookie *http.Cookie) {
-	r.cookie = cookie
+// SetContentType can be used to declare the media type, and optionally the
+// charset, of the body; when not declared gorest detects it from the body.
+func (r *StandardResponse) SetContentType(mediaType, charset string) {
+	if charset == "" {
+		r.contentType = mediaType
+		return
+	}
+	r.contentType = mime.FormatMediaType(mediaType, map[string]string{"charset": charset})
 }
 
-// GetCookie will be used by gorest core to retrieve the cookie to be sent in
-// the HTTP response writer.
-func (r *StandardResponse) GetCookie() *http.Cookie {
-	return r.cookie
+// GetContentType will be used by gorest core to retrieve the Content-Type to
+// be sent in the HTTP response writer.
+func (r *StandardResponse) GetContentType() string {
+	return r.contentType
 }
 
-// SetHeaders can be used to set a custom set of headers that will be set in
-// the HTTP response writer.
-func (r *StandardResponse) SetHeaders(headers http.Header) {
-	r.headers = headers
+// SetStatusCode can be used to set the status code of the response returned
+// by the error-returning resource methods, which otherwise is 200 OK.
+func (r *StandardResponse) SetStatusCode(code int) {
+	r.code = code
 }
 
-// GetHeaders will be used by gorest core to retrieve the headers to be sent in
-// the HTTP response writer.
-func (r *StandardResponse) GetHeaders() http.Header {
-	return r.headers
+// GetStatusCode will be used by gorest core to retrieve the status code of
+// the response returned by the error-returning resource methods.
+func (r *StandardResponse) GetStatusCode() int {
+	return r.code
 }
@@ -128,3 +128,75 @@ func TestStandardResponseGetHeaders(t *testing.T) {
 		t.Fatalf("Unexpected header \"A\" value. Expected: %s - Found: %s.", "B", r.GetHeaders().Get("A"))
 	}
 }
+
+// TestStandardResponseStatusCode verifies the status code setter and getter.
+func TestStandardResponseStatusCode(t *testing.T) {
+	r := NewStandardResponse()
+	if r.GetStatusCode() != 0 {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", 0, r.GetStatusCode())
+	}
+	r.SetStatusCode(http.StatusCreated)
+	if r.GetStatusCode() != http.StatusCreated {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusCreated, r.GetStatusCode())
+	}
+}
+
+// TestStandardResponseContentType verifies the Content-Type setter and getter.
+func TestStandardResponseContentType(t *testing.T) {
+	r := NewStandardResponse()
+	if r.GetContentType() != "" {
+		t.Fatalf("Unexpected Content-Type. Expected: '' - Found: %s.", r.GetContentType())
+	}
+
+	r.SetContentType("text/html", "utf-8")
+	if expected := "text/html; charset=utf-8"; r.GetContentType() != expected {
+		t.Fatalf("Unexpected Content-Type. Expected: %s - Found: %s.", expected, r.GetContentType())
+	}
+
+	r.SetContentType("image/png", "")
+	if expected := "image/png"; r.GetContentType() != expected {
+		t.Fatalf("Unexpected Content-Type. Expected: %s - Found: %s.", expected, r.GetContentType())
+	}
+}
+
+// TestStandardResponseAddHeader verifies that AddHeader keeps the existing
+// values.
+func TestStandardResponseAddHeader(t *testing.T) {
+	r := NewStandardResponse()
+	r.AddHeader("Link", "</a>; rel=next")
+	r.AddHeader("Link", "</b>; rel=prev")
+
+	links := r.GetHeaders()["Link"]
+	if len(links) != 2 || links[0] != "</a>; rel=next" || links[1] != "</b>; rel=prev" {
+		t.Fatalf("Unexpected Link values. Expected: 2 values - Found: %v.", links)
+	}
+}
+
+// TestStandardResponseCookies verifies that the cookies set and added are
+// all returned, with the deleted ones expired.
+func TestStandardResponseCookies(t *testing.T) {
+	r := NewStandardResponse()
+	if cookies := r.GetCookies(); len(cookies) != 0 {
+		t.Fatalf("Unexpected cookies. Expected: none - Found: %d.", len(cookies))
+	}
+
+	r.AddCookie(&http.Cookie{Name: "b"})
+	r.SetCookie(&http.Cookie{Name: "a"})
+	r.DeleteCookie("c", "/")
+
+	cookies := r.GetCookies()
+	if len(cookies) != 3 {
+		t.Fatalf("Unexpected cookies count. Expected: %d - Found: %d.", 3, len(cookies))
+	}
+	for i, name := range []string{"a", "b", "c"} {
+		if cookies[i].Name != name {
+			t.Fatalf("Unexpected cookie name. Expected: %s - Found: %s.", name, cookies[i].Name)
+		}
+	}
+	if cookies[2].MaxAge >= 0 || cookies[2].Path != "/" {
+		t.Fatalf("Unexpected deleted cookie. Expected: expired - Found: %+v.", cookies[2])
+	}
+	if r.GetCookie().Name != "a" {
+		t.Fatalf("Unexpected cookie name. Expected: %s - Found: %s.", "a", r.GetCookie().Name)
+	}
+}
@@ -0,0 +1,159 @@
+package gorest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Streamer is the interface implemented by the responses whose body is
+// streamed instead of being retrieved at once with GetBody. The body is sent
+// using chunked transfer encoding as it is written, and no ETag is computed.
+type Streamer interface {
+	Stream(w io.Writer) error
+}
+
+// StreamErrorHandler is invoked when streaming a response body fails, since
+// the status line has already been sent the error cannot be reported to the
+// client.
+type StreamErrorHandler func(r *http.Request, err error)
+
+// StreamResponse is a response whose body is streamed to the client, either
+// copying it from a reader or producing it with a write callback.
+type StreamResponse struct {
+	responseHeaders
+	responseCachePolicy
+	reader        io.Reader
+	write         func(w io.Writer) error
+	contentType   string
+	flushInterval time.Duration
+}
+
+// NewStreamResponse creates a new StreamResponse copying the body from the
+// reader, which is closed at the end if it implements io.Closer.
+func NewStreamResponse(reader io.Reader) *StreamResponse {
+	return &StreamResponse{reader: reader}
+}
+
+// NewStreamResponseFunc creates a new StreamResponse whose body is written by
+// the callback.
+func NewStreamResponseFunc(write func(w io.Writer) error) *StreamResponse {
+	return &StreamResponse{write: write}
+}
+
+// Stream writes the body into the writer.
+func (r *StreamResponse) Stream(w io.Writer) error {
+	if r.write != nil {
+		return r.write(w)
+	}
+	defer r.Close()
+	_, err := io.Copy(w, r.reader)
+	return err
+}
+
+// Close closes the reader, if it implements io.Closer. It is used by gorest
+// core when the body is not sent, e.g. for HEAD requests.
+func (r *StreamResponse) Close() error {
+	if closer, ok := r.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// GetBody reads the whole stream, it is used only when the response is not
+// served by gorest.
+func (r *StreamResponse) GetBody() ([]byte, error) {
+	if r.write == nil {
+		defer r.Close()
+		return io.ReadAll(r.reader)
+	}
+	var body bytes.Buffer
+	err := r.write(&body)
+	return body.Bytes(), err
+}
+
+// SetFlushInterval can be used to set the minimum interval between the
+// flushes of the written data, by default the data is flushed on each write.
+func (r *StreamResponse) SetFlushInterval(interval time.Duration) {
+	r.flushInterval = interval
+}
+
+// GetFlushInterval will be used by gorest core to retrieve the minimum
+// interval between the flushes of the written data.
+func (r *StreamResponse) GetFlushInterval() time.Duration {
+	return r.flushInterval
+}
+
+// SetContentType can be used to declare the media type of the body, since the
+// body is not available beforehand it is sniffed by net/http otherwise.
+func (r *StreamResponse) SetContentType(contentType string) {
+	r.contentType = contentType
+}
+
+// GetContentType will be used by gorest core to retrieve the Content-Type to
+// be sent in the HTTP response writer.
+func (r *StreamResponse) GetContentType() string {
+	return r.contentType
+}
+
+// SetStreamErrorHandler sets the function invoked when streaming a response
+// body fails, if nil the error is logged.
+func (h *RestHandler) SetStreamErrorHandler(handler StreamErrorHandler) {
+	h.streamErrorHandler = handler
+}
+
+// writeStream writes the headers and streams the body of the response.
+func (h *RestHandler) writeStream(w *responseWriter, r *http.Request, code int, response Response, streamer Streamer) {
+	setResponseHeaders(w, response)
+	// The length is unknown, the body is sent with chunked transfer encoding.
+	w.Header().Del("Content-Length")
+	w.WriteHeader(code)
+
+	if r.Method == http.MethodHead {
+		if closer, ok := streamer.(io.Closer); ok {
+			closer.Close()
+		}
+		return
+	}
+
+	fw := &flushWriter{w: w}
+	if res, ok := response.(interface{ GetFlushInterval() time.Duration }); ok {
+		fw.interval = res.GetFlushInterval()
+	}
+	err := streamer.Stream(fw)
+	w.Flush()
+	if err != nil {
+		h.streamError(r, err)
+	}
+}
+
+// streamError reports the error occurred while streaming a response body.
+func (h *RestHandler) streamError(r *http.Request, err error) {
+	if h.streamErrorHandler != nil {
+		h.streamErrorHandler(r, err)
+		return
+	}
+	h.logf("gorest: failed streaming response body for %s %s: %s", r.Method, r.URL.Path, err.Error())
+}
+
+// flushWriter flushes the written data when at least the interval has elapsed
+// since the previous flush.
+type flushWriter struct {
+	w         *responseWriter
+	interval  time.Duration
+	lastFlush time.Time
+}
+
+// Write writes the data, flushing it if needed.
+func (f *flushWriter) Write(data []byte) (int, error) {
+	n, err := f.w.Write(data)
+	if err != nil {
+		return n, err
+	}
+	if now := time.Now(); now.Sub(f.lastFlush) >= f.interval {
+		f.w.Flush()
+		f.lastFlush = now
+	}
+	return n, nil
+}
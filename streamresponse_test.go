@@ -0,0 +1,159 @@
+package gorest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testStreamResource returns the response built by the function.
+type testStreamResource struct {
+	response func() Response
+}
+
+func (t testStreamResource) Get(r *http.Request) (int, Response) {
+	return http.StatusOK, t.response()
+}
+
+// testReadCloser records whether it has been closed.
+type testReadCloser struct {
+	io.Reader
+	closed bool
+}
+
+func (t *testReadCloser) Close() error {
+	t.closed = true
+	return nil
+}
+
+// TestStreamResponseReader verifies that the reader body is streamed with
+// chunked transfer encoding and without ETag.
+func TestStreamResponseReader(t *testing.T) {
+	body := strings.Repeat("gorest", 10000)
+	reader := &testReadCloser{Reader: strings.NewReader(body)}
+
+	h := New()
+	h.RegisterRoute(NewRoute(testStreamResource{func() Response {
+		response := NewStreamResponse(reader)
+		response.SetContentType("text/plain")
+		return response
+	}}, "/"))
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	data, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if string(data) != body {
+		t.Fatalf("Unexpected body length. Expected: %d - Found: %d.", len(body), len(data))
+	}
+	if len(res.TransferEncoding) != 1 || res.TransferEncoding[0] != "chunked" {
+		t.Fatalf("Unexpected Transfer-Encoding. Expected: chunked - Found: %v.", res.TransferEncoding)
+	}
+	if etag := res.Header.Get("ETag"); etag != "" {
+		t.Fatalf("Unexpected ETag. Expected: '' - Found: %s.", etag)
+	}
+	if contentType := res.Header.Get("Content-Type"); contentType != "text/plain" {
+		t.Fatalf("Unexpected Content-Type. Expected: text/plain - Found: %s.", contentType)
+	}
+	if !reader.closed {
+		t.Fatalf("Reader should have been closed.")
+	}
+}
+
+// TestStreamResponseFlush verifies that the written data is flushed.
+func TestStreamResponseFlush(t *testing.T) {
+	flushed := false
+	w := httptest.NewRecorder()
+
+	h := New()
+	h.RegisterRoute(NewRoute(testStreamResource{func() Response {
+		return NewStreamResponseFunc(func(out io.Writer) error {
+			fmt.Fprint(out, "first")
+			flushed = w.Flushed
+			fmt.Fprint(out, "second")
+			return nil
+		})
+	}}, "/"))
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !flushed {
+		t.Fatalf("Data should have been flushed after the first write.")
+	}
+	if body := w.Body.String(); body != "firstsecond" {
+		t.Fatalf("Unexpected body. Expected: firstsecond - Found: %s.", body)
+	}
+}
+
+// TestStreamResponseError verifies that the streaming errors are reported to
+// the StreamErrorHandler.
+func TestStreamResponseError(t *testing.T) {
+	streamErr := errors.New("stream failed")
+	var reported error
+
+	h := New()
+	h.SetStreamErrorHandler(func(r *http.Request, err error) {
+		reported = err
+	})
+	h.RegisterRoute(NewRoute(testStreamResource{func() Response {
+		return NewStreamResponseFunc(func(out io.Writer) error {
+			fmt.Fprint(out, "partial")
+			return streamErr
+		})
+	}}, "/"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if reported != streamErr {
+		t.Fatalf("Unexpected reported error. Expected: %v - Found: %v.", streamErr, reported)
+	}
+}
+
+// TestStreamResponseHead verifies that HEAD requests do not consume the
+// stream, which is closed.
+func TestStreamResponseHead(t *testing.T) {
+	reader := &testReadCloser{Reader: strings.NewReader("body")}
+
+	h := New()
+	h.RegisterRoute(NewRoute(testStreamResource{func() Response {
+		return NewStreamResponse(reader)
+	}}, "/"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/", nil))
+
+	if w.Body.Len() != 0 {
+		t.Fatalf("Unexpected body. Expected: '' - Found: %s.", w.Body.String())
+	}
+	if !reader.closed {
+		t.Fatalf("Reader should have been closed.")
+	}
+}
+
+// TestStreamResponseGetBody verifies that GetBody reads the whole stream.
+func TestStreamResponseGetBody(t *testing.T) {
+	body, err := NewStreamResponse(strings.NewReader("body")).GetBody()
+	if err != nil || string(body) != "body" {
+		t.Fatalf("Unexpected body. Expected: body - Found: %s (%v).", body, err)
+	}
+
+	body, err = NewStreamResponseFunc(func(w io.Writer) error {
+		_, err := io.WriteString(w, "func")
+		return err
+	}).GetBody()
+	if err != nil || string(body) != "func" {
+		t.Fatalf("Unexpected body. Expected: func - Found: %s (%v).", body, err)
+	}
+}
@@ -0,0 +1,249 @@
+package gorest
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ErrRequestTooLarge is returned when the request body exceeds the maximum
+// size configured for the route.
+var ErrRequestTooLarge = NewError(http.StatusRequestEntityTooLarge, "request body too large")
+
+// UploadConfig defines the limits and the storage of the uploads accepted by
+// a route, the zero values mean no limit.
+type UploadConfig struct {
+	MaxTotalSize int64         // Maximum size of the whole request body.
+	MaxFileSize  int64         // Maximum size of each file.
+	MaxFiles     int           // Maximum number of files.
+	AllowedTypes []string      // Allowed media types, e.g. "image/*", verified by sniffing the content.
+	Storage      UploadStorage // Storage of the files, a DiskStorage in the temporary directory if nil.
+}
+
+// UploadedFile describes a file uploaded with a multipart form.
+type UploadedFile struct {
+	Field       string // Name of the form field.
+	Filename    string // Name of the file provided by the client.
+	ContentType string // Media type detected from the content.
+	Size        int64  // Size of the file.
+	Location    string // Location of the file in the storage.
+}
+
+// Upload is the content of a multipart form, with the files already saved in
+// the storage.
+type Upload struct {
+	Values url.Values
+	Files  []*UploadedFile
+}
+
+// UploadStorage stores the uploaded files.
+type UploadStorage interface {
+	// Save stores the content of the file, setting its Location.
+	Save(file *UploadedFile, content io.Reader) error
+	// Delete removes the stored file.
+	Delete(file *UploadedFile) error
+}
+
+// DiskStorage is an UploadStorage saving the files in a local directory.
+type DiskStorage struct {
+	Dir string // Directory the files are saved into, the temporary one if empty.
+}
+
+// NewDiskStorage creates a new DiskStorage saving the files in provided
+// directory.
+func NewDiskStorage(dir string) *DiskStorage {
+	return &DiskStorage{Dir: dir}
+}
+
+// Save stores the content in a new file, the Location is its path.
+func (s *DiskStorage) Save(file *UploadedFile, content io.Reader) error {
+	f, err := os.CreateTemp(s.Dir, "gorest-upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	file.Location = f.Name()
+	return nil
+}
+
+// Delete removes the file.
+func (s *DiskStorage) Delete(file *UploadedFile) error {
+	return os.Remove(file.Location)
+}
+
+// ReadUploads reads the multipart form of the request streaming the files to
+// the storage configured for the route, according to its limits. It returns
+// ErrRequestTooLarge (413) when a size or count limit is exceeded and a 415
+// Error when a file media type is not allowed; the files already stored are
+// then deleted. The request body can be read only once.
+func ReadUploads(r *http.Request) (*Upload, error) {
+	var config UploadConfig
+	if info, ok := GetRouteInfo(r); ok && info.Route != nil && info.Route.GetUploadConfig() != nil {
+		config = *info.Route.GetUploadConfig()
+	}
+	if config.Storage == nil {
+		config.Storage = &DiskStorage{}
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		if errors.Is(err, http.ErrNotMultipart) {
+			return nil, ErrUnsupportedMediaType
+		}
+		return nil, &DecodeError{MediaType: "multipart/form-data", Err: err}
+	}
+
+	upload := &Upload{Values: url.Values{}}
+	if err := readParts(reader, &config, upload); err != nil {
+		for _, file := range upload.Files {
+			config.Storage.Delete(file)
+		}
+		upload.Files = nil
+		if errors.Is(err, errLimitExceeded) {
+			return nil, ErrRequestTooLarge
+		}
+		return nil, err
+	}
+	return upload, nil
+}
+
+// readParts reads the parts of the form into the upload.
+func readParts(reader *multipart.Reader, config *UploadConfig, upload *Upload) error {
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return uploadError(err)
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(part)
+			if err != nil {
+				return uploadError(err)
+			}
+			upload.Values.Add(part.FormName(), string(value))
+			continue
+		}
+
+		if config.MaxFiles > 0 && len(upload.Files) >= config.MaxFiles {
+			return errLimitExceeded
+		}
+		if err := saveFile(part, config, upload); err != nil {
+			return err
+		}
+	}
+}
+
+// saveFile verifies the media type of the file part and streams it to the
+// storage.
+func saveFile(part *multipart.Part, config *UploadConfig, upload *Upload) error {
+	content := bufio.NewReaderSize(part, 512)
+	head, err := content.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return uploadError(err)
+	}
+
+	file := &UploadedFile{
+		Field:       part.FormName(),
+		Filename:    part.FileName(),
+		ContentType: http.DetectContentType(head),
+	}
+	if !allowedMediaType(file.ContentType, config.AllowedTypes) {
+		return Errorf(http.StatusUnsupportedMediaType, "file %s media type %s is not allowed", file.Filename, file.ContentType)
+	}
+
+	counter := &countingReader{reader: content, limit: config.MaxFileSize}
+	if err := config.Storage.Save(file, counter); err != nil {
+		return uploadError(err)
+	}
+	file.Size = counter.count
+	upload.Files = append(upload.Files, file)
+	return nil
+}
+
+// allowedMediaType verifies whether the detected content type matches one of
+// the allowed media types, any type is allowed if none is listed.
+func allowedMediaType(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, candidate := range allowed {
+		candidate = strings.ToLower(candidate)
+		if candidate == mediaType || strings.HasSuffix(candidate, "/*") && strings.HasPrefix(mediaType, candidate[:len(candidate)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// errLimitExceeded is returned by the readers when a size limit is exceeded.
+var errLimitExceeded = errors.New("upload limit exceeded")
+
+// uploadError converts the errors occurred reading the form, keeping the
+// exceeded limits.
+func uploadError(err error) error {
+	if errors.Is(err, errLimitExceeded) {
+		return err
+	}
+	var gorestErr *Error
+	if errors.As(err, &gorestErr) {
+		return err
+	}
+	return &DecodeError{MediaType: "multipart/form-data", Err: err}
+}
+
+// countingReader counts the bytes read failing with errLimitExceeded when
+// they exceed the limit, if positive.
+type countingReader struct {
+	reader io.Reader
+	limit  int64
+	count  int64
+}
+
+// Read reads from the underlying reader, the data exceeding the limit is
+// discarded so that it cannot be consumed along with the error.
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	if c.limit > 0 && c.count+int64(n) > c.limit {
+		n = int(c.limit - c.count)
+		c.count = c.limit
+		return n, errLimitExceeded
+	}
+	c.count += int64(n)
+	return n, err
+}
+
+// limitedBody is a request body failing with errLimitExceeded when more than
+// the limit is read.
+type limitedBody struct {
+	countingReader
+	io.Closer
+}
+
+// limitBody limits the size of the request body according to the upload
+// configuration of the route.
+func limitBody(r *http.Request, route *Route) {
+	if config := route.GetUploadConfig(); config != nil && config.MaxTotalSize > 0 && r.Body != nil {
+		r.Body = &limitedBody{countingReader{reader: r.Body, limit: config.MaxTotalSize}, r.Body}
+	}
+}
@@ -0,0 +1,139 @@
+package gorest
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testPNG is the signature of a PNG image, enough to be sniffed.
+const testPNG = "\x89PNG\r\n\x1a\n"
+
+// testUploadResource reads the uploads of the POST requests.
+type testUploadResource struct {
+	upload *Upload
+}
+
+func (t *testUploadResource) PostErr(r *http.Request) (Response, error) {
+	upload, err := ReadUploads(r)
+	if err != nil {
+		return nil, err
+	}
+	t.upload = upload
+	return nil, nil
+}
+
+// newUploadBody creates a multipart body with the fields and files.
+func newUploadBody(t *testing.T, fields map[string]string, files map[string]string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for key, value := range fields {
+		mw.WriteField(key, value)
+	}
+	for name, content := range files {
+		part, err := mw.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s.", err.Error())
+		}
+		part.Write([]byte(content))
+	}
+	mw.Close()
+	return &body, mw.FormDataContentType()
+}
+
+// postUpload posts the body to a route with provided upload configuration.
+func postUpload(config UploadConfig, body *bytes.Buffer, contentType string) (*testUploadResource, *httptest.ResponseRecorder) {
+	res := &testUploadResource{}
+	h := New()
+	h.SetLogger(discardLogger)
+	h.RegisterRoute(NewRoute(res, "/").SetUploadConfig(config))
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return res, w
+}
+
+// TestReadUploads verifies that the files are stored with their values.
+func TestReadUploads(t *testing.T) {
+	dir := t.TempDir()
+	body, contentType := newUploadBody(t, map[string]string{"title": "holiday"}, map[string]string{"photo.png": testPNG + "data"})
+
+	res, w := postUpload(UploadConfig{
+		MaxFileSize:  100,
+		MaxFiles:     1,
+		AllowedTypes: []string{"image/*"},
+		Storage:      NewDiskStorage(dir),
+	}, body, contentType)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if title := res.upload.Values.Get("title"); title != "holiday" {
+		t.Fatalf("Unexpected title. Expected: holiday - Found: %s.", title)
+	}
+	if len(res.upload.Files) != 1 {
+		t.Fatalf("Unexpected files count. Expected: %d - Found: %d.", 1, len(res.upload.Files))
+	}
+	file := res.upload.Files[0]
+	if file.Field != "file" || file.Filename != "photo.png" || file.ContentType != "image/png" || file.Size != int64(len(testPNG)+4) {
+		t.Fatalf("Unexpected file. Found: %+v.", file)
+	}
+	data, err := os.ReadFile(file.Location)
+	if err != nil || string(data) != testPNG+"data" {
+		t.Fatalf("Unexpected stored content. Found: %q (%v).", data, err)
+	}
+	if !strings.HasPrefix(file.Location, dir) {
+		t.Fatalf("Unexpected location. Expected: in %s - Found: %s.", dir, file.Location)
+	}
+}
+
+// TestReadUploadsLimits verifies that 413 and 415 are returned when the
+// limits are exceeded, deleting the stored files.
+func TestReadUploadsLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		config UploadConfig
+		files  map[string]string
+		code   int
+	}{
+		{"file size", UploadConfig{MaxFileSize: 10}, map[string]string{"a.txt": strings.Repeat("a", 11)}, http.StatusRequestEntityTooLarge},
+		{"total size", UploadConfig{MaxTotalSize: 100}, map[string]string{"a.txt": strings.Repeat("a", 200)}, http.StatusRequestEntityTooLarge},
+		{"files count", UploadConfig{MaxFiles: 1}, map[string]string{"a.txt": "a", "b.txt": "b"}, http.StatusRequestEntityTooLarge},
+		{"media type", UploadConfig{AllowedTypes: []string{"image/png"}}, map[string]string{"a.png": "not an image"}, http.StatusUnsupportedMediaType},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		test.config.Storage = NewDiskStorage(dir)
+		body, contentType := newUploadBody(t, nil, test.files)
+
+		_, w := postUpload(test.config, body, contentType)
+		if w.Code != test.code {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d.", test.name, test.code, w.Code)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("Unexpected stored files for %s. Expected: none - Found: %d.", test.name, len(entries))
+		}
+	}
+}
+
+// TestUploadConfigLimitsForms verifies that the total size limit applies to
+// the URL-encoded forms and that non multipart requests are rejected.
+func TestUploadConfigLimitsForms(t *testing.T) {
+	body := bytes.NewBufferString("a=" + strings.Repeat("a", 200))
+	_, w := postUpload(UploadConfig{MaxTotalSize: 100}, body, "application/x-www-form-urlencoded")
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusRequestEntityTooLarge, w.Code)
+	}
+
+	_, w = postUpload(UploadConfig{}, bytes.NewBufferString("{}"), "application/json")
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusUnsupportedMediaType, w.Code)
+	}
+}
@@ -0,0 +1,377 @@
+package gorest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidatorFunc validates a value against the rule parameter, returning an
+// error describing why the value is not valid. Pointers are dereferenced
+// before invoking the validators and nil pointers are validated only by the
+// "required" rule. Errors wrapping ErrInvalidRule report a malformed rule
+// parameter instead of an invalid value.
+type ValidatorFunc func(value interface{}, param string) error
+
+// ErrInvalidRule is returned, wrapped, by the validators when the parameter
+// of a rule is malformed. Like unknown rules, malformed rules are programming
+// errors, so they are not reported as field errors but they are mapped to
+// 500 Internal Server Error.
+var ErrInvalidRule = errors.New("invalid validation rule")
+
+// Validator validates structs according to their "validate" tags, which list
+// comma separated rules, e.g. `validate:"required,min=1,max=10"`.
+//
+// The following rules are available by default:
+//   - required: the value must not be the zero value;
+//   - min=N, max=N: numbers must be within the bounds, strings (in runes),
+//     slices and maps must have a length within the bounds;
+//   - len=N: strings (in runes), slices and maps must have exactly length N;
+//   - enum=a|b|c: the value must be one of the listed ones;
+//   - email: the value must be a valid email address;
+//   - regex=PATTERN: the string must match the pattern, since the pattern can
+//     contain commas it must be the last rule of the tag.
+//
+// Nested structs, and slices, arrays and maps of structs, are validated
+// recursively. Field errors are identified by JSON pointers built from the
+// "json" tags.
+type Validator struct {
+	mu         sync.RWMutex
+	validators map[string]ValidatorFunc
+}
+
+// defaultValidator is the Validator used when no custom validator has been
+// registered.
+var defaultValidator = NewValidator()
+
+// regexpCache caches the compiled regex rule patterns.
+var regexpCache sync.Map
+
+// NewValidator creates a new Validator with the default rules.
+func NewValidator() *Validator {
+	return &Validator{validators: map[string]ValidatorFunc{
+		"min":   validateMin,
+		"max":   validateMax,
+		"len":   validateLen,
+		"enum":  validateEnum,
+		"email": validateEmail,
+		"regex": validateRegex,
+	}}
+}
+
+// Register registers a custom rule, replacing the existing one with the same
+// name if any. The "required" rule cannot be replaced.
+func (v *Validator) Register(name string, fn ValidatorFunc) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.validators[name] = fn
+}
+
+// Validate validates the struct, or pointer to struct, returning a
+// ValidationError listing all the invalid fields.
+func (v *Validator) Validate(value interface{}) error {
+	var fields []FieldError
+	if err := v.validateValue(reflect.ValueOf(value), "", &fields); err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validateValue recursively validates the structs contained in value.
+func (v *Validator) validateValue(value reflect.Value, pointer string, fields *[]FieldError) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return v.validateStruct(value, pointer, fields)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.validateValue(value.Index(i), pointer+"/"+strconv.Itoa(i), fields); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			keyPointer := pointer + "/" + escapePointer(fmt.Sprint(key.Interface()))
+			if err := v.validateValue(value.MapIndex(key), keyPointer, fields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateStruct validates the fields of the struct according to their tags.
+// The fields promoted by the embedded structs are validated as fields of the
+// parent struct, matching their JSON representation.
+func (v *Validator) validateStruct(value reflect.Value, pointer string, fields *[]FieldError) error {
+	rt := value.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if isEmbeddedStruct(field, "json") {
+			if !value.CanAddr() {
+				addressable := reflect.New(rt).Elem()
+				addressable.Set(value)
+				value = addressable
+			}
+			if err := v.validateValue(exportedField(value, i), pointer, fields); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name := fieldName(field, "json")
+		if name == "-" {
+			continue
+		}
+		fieldPointer := pointer + "/" + escapePointer(name)
+		fieldValue := value.Field(i)
+
+		valid, err := v.validateField(fieldValue, field.Tag.Get("validate"), fieldPointer, fields)
+		if err != nil {
+			return err
+		}
+		if valid {
+			if err := v.validateValue(fieldValue, fieldPointer, fields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateField applies the rules to the field value, appending the field
+// errors; it returns whether the value is valid.
+func (v *Validator) validateField(value reflect.Value, tag, pointer string, fields *[]FieldError) (bool, error) {
+	for _, rule := range parseRules(tag) {
+		name, param := rule[0], rule[1]
+		if name == "required" {
+			if isZero(value) {
+				*fields = append(*fields, FieldError{Pointer: pointer, Message: "is required"})
+				return false, nil
+			}
+			continue
+		}
+
+		v.mu.RLock()
+		fn, ok := v.validators[name]
+		v.mu.RUnlock()
+		if !ok {
+			return false, fmt.Errorf("%s: unknown validation rule %q", pointer, name)
+		}
+
+		target := value
+		for target.Kind() == reflect.Ptr || target.Kind() == reflect.Interface {
+			if target.IsNil() {
+				return true, nil
+			}
+			target = target.Elem()
+		}
+		if err := fn(target.Interface(), param); err != nil {
+			if errors.Is(err, ErrInvalidRule) {
+				return false, fmt.Errorf("%s: %w", pointer, err)
+			}
+			*fields = append(*fields, FieldError{Pointer: pointer, Message: err.Error()})
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parseRules splits the validate tag into rule name and parameter pairs, the
+// regex rule consumes the rest of the tag.
+func parseRules(tag string) [][2]string {
+	var rules [][2]string
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regex=") {
+			rule, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			rule, tag = tag[:i], tag[i+1:]
+		} else {
+			rule, tag = tag, ""
+		}
+
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+		if name != "" {
+			rules = append(rules, [2]string{name, param})
+		}
+	}
+	return rules
+}
+
+// isZero verifies whether the value is the zero value of its type, empty
+// slices and maps are considered zero.
+func isZero(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
+// escapePointer escapes a JSON pointer reference token.
+func escapePointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// size returns the number to compare with the min and max bounds: the value
+// itself for numbers and the length for strings, slices and maps.
+func size(value interface{}) (float64, bool, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), false, nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), false, nil
+	case reflect.String:
+		return float64(utf8.RuneCountInString(rv.String())), true, nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(rv.Len()), true, nil
+	}
+	return 0, false, fmt.Errorf("cannot be compared with a bound")
+}
+
+func validateMin(value interface{}, param string) error {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("%w: min=%s", ErrInvalidRule, param)
+	}
+	n, isLength, err := size(value)
+	if err != nil {
+		return err
+	}
+	if n < bound {
+		if isLength {
+			return fmt.Errorf("length must be at least %s", param)
+		}
+		return fmt.Errorf("must be at least %s", param)
+	}
+	return nil
+}
+
+func validateMax(value interface{}, param string) error {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("%w: max=%s", ErrInvalidRule, param)
+	}
+	n, isLength, err := size(value)
+	if err != nil {
+		return err
+	}
+	if n > bound {
+		if isLength {
+			return fmt.Errorf("length must be at most %s", param)
+		}
+		return fmt.Errorf("must be at most %s", param)
+	}
+	return nil
+}
+
+func validateLen(value interface{}, param string) error {
+	length, err := strconv.Atoi(param)
+	if err != nil {
+		return fmt.Errorf("%w: len=%s", ErrInvalidRule, param)
+	}
+	n, isLength, err := size(value)
+	if err != nil || !isLength {
+		return errors.New("has no length")
+	}
+	if int(n) != length {
+		return fmt.Errorf("length must be %d", length)
+	}
+	return nil
+}
+
+func validateEnum(value interface{}, param string) error {
+	allowed := strings.Split(param, "|")
+	formatted := fmt.Sprint(value)
+	for _, candidate := range allowed {
+		if formatted == candidate {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+}
+
+func validateEmail(value interface{}, _ string) error {
+	s, ok := value.(string)
+	if !ok {
+		return errors.New("must be a string")
+	}
+	if address, err := mail.ParseAddress(s); err != nil || address.Address != s {
+		return errors.New("must be a valid email address")
+	}
+	return nil
+}
+
+func validateRegex(value interface{}, param string) error {
+	s, ok := value.(string)
+	if !ok {
+		return errors.New("must be a string")
+	}
+
+	cached, ok := regexpCache.Load(param)
+	if !ok {
+		re, err := regexp.Compile(param)
+		if err != nil {
+			return fmt.Errorf("%w: regex=%s: %s", ErrInvalidRule, param, err.Error())
+		}
+		cached, _ = regexpCache.LoadOrStore(param, re)
+	}
+	if !cached.(*regexp.Regexp).MatchString(s) {
+		return fmt.Errorf("must match %s", param)
+	}
+	return nil
+}
+
+// RegisterValidator registers a custom validation rule for the payloads of
+// the handler, see Validator for the rules available by default.
+func (h *RestHandler) RegisterValidator(name string, fn ValidatorFunc) {
+	if h.validator == nil {
+		h.validator = NewValidator()
+		h.validators = make(map[string]ValidatorFunc)
+	}
+	h.validator.Register(name, fn)
+	h.validators[name] = fn
+}
+
+// getValidator returns the Validator of the handler.
+func (h *RestHandler) getValidator() *Validator {
+	if h.validator == nil {
+		return defaultValidator
+	}
+	return h.validator
+}
+
+// Validate validates the value using the rules registered in the RestHandler
+// serving the request, returning a ValidationError listing all the invalid
+// fields. The payloads decoded by gorest are validated automatically.
+func Validate(r *http.Request, value interface{}) error {
+	if h := servingHandler(r); h != nil {
+		return h.getValidator().Validate(value)
+	}
+	return defaultValidator.Validate(value)
+}
@@ -0,0 +1,276 @@
+package gorest
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"regex=^[0-9]{5}(,[0-9]{4})?$"`
+}
+
+type testUser struct {
+	Name      string                  `json:"name" validate:"required,min=2,max=5"`
+	Email     string                  `json:"email" validate:"email"`
+	Age       int                     `json:"age" validate:"min=18,max=99"`
+	Role      string                  `json:"role" validate:"enum=admin|user"`
+	Code      string                  `json:"code,omitempty" validate:"len=3"`
+	Tags      []string                `json:"tags" validate:"max=2"`
+	Nickname  *string                 `json:"nickname" validate:"min=3"`
+	Address   testAddress             `json:"address"`
+	Previous  []testAddress           `json:"previous"`
+	Labeled   map[string]*testAddress `json:"labeled"`
+	Ignored   string                  `json:"-" validate:"required"`
+	NoJSONTag string                  `validate:"required"`
+}
+
+// TestValidatorValid verifies that valid structs pass the validation.
+func TestValidatorValid(t *testing.T) {
+	user := testUser{
+		Name:      "fred",
+		Email:     "fred@example.com",
+		Age:       33,
+		Role:      "admin",
+		Code:      "abc",
+		Tags:      []string{"a", "b"},
+		Address:   testAddress{City: "Milan", Zip: "20100"},
+		Previous:  []testAddress{{City: "Turin", Zip: "10100,1234"}},
+		NoJSONTag: "x",
+	}
+	if err := NewValidator().Validate(&user); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+}
+
+// TestValidatorInvalid verifies that all the field errors are listed.
+func TestValidatorInvalid(t *testing.T) {
+	short := "ab"
+	user := testUser{
+		Name:     "frederick",
+		Email:    "Fred <fred@example.com>",
+		Age:      12,
+		Role:     "guest",
+		Code:     "abcd",
+		Tags:     []string{"a", "b", "c"},
+		Nickname: &short,
+		Address:  testAddress{Zip: "abc"},
+		Previous: []testAddress{{City: "Turin", Zip: "10100"}, {Zip: "1"}},
+		Labeled:  map[string]*testAddress{"a/b": {Zip: "20100"}},
+	}
+
+	err := NewValidator().Validate(user)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Unexpected error. Expected ValidationError - Found: %v.", err)
+	}
+
+	expected := []FieldError{
+		{"/name", "length must be at most 5"},
+		{"/email", "must be a valid email address"},
+		{"/age", "must be at least 18"},
+		{"/role", "must be one of admin, user"},
+		{"/code", "length must be 3"},
+		{"/tags", "length must be at most 2"},
+		{"/nickname", "length must be at least 3"},
+		{"/address/city", "is required"},
+		{"/address/zip", "must match ^[0-9]{5}(,[0-9]{4})?$"},
+		{"/previous/1/city", "is required"},
+		{"/previous/1/zip", "must match ^[0-9]{5}(,[0-9]{4})?$"},
+		{"/labeled/a~1b/city", "is required"},
+		{"/NoJSONTag", "is required"},
+	}
+	if len(validationErr.Fields) != len(expected) {
+		t.Fatalf("Unexpected field errors. Expected: %+v - Found: %+v.", expected, validationErr.Fields)
+	}
+	for i, field := range expected {
+		if validationErr.Fields[i] != field {
+			t.Fatalf("Unexpected field error %d. Expected: %+v - Found: %+v.", i, field, validationErr.Fields[i])
+		}
+	}
+}
+
+type testBase struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type TestExportedBase struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// TestValidatorEmbedded verifies that the fields of the embedded structs,
+// either exported or not, are validated as fields of the parent struct.
+func TestValidatorEmbedded(t *testing.T) {
+	type payload struct {
+		testBase
+		*TestExportedBase
+		Named testAddress `json:"named"`
+	}
+
+	err := NewValidator().Validate(payload{TestExportedBase: &TestExportedBase{}, Named: testAddress{City: "Milan", Zip: "20100"}})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Unexpected error. Expected ValidationError - Found: %v.", err)
+	}
+	expected := []FieldError{
+		{"/id", "is required"},
+		{"/name", "is required"},
+	}
+	if len(validationErr.Fields) != len(expected) {
+		t.Fatalf("Unexpected field errors. Expected: %+v - Found: %+v.", expected, validationErr.Fields)
+	}
+	for i, field := range expected {
+		if validationErr.Fields[i] != field {
+			t.Fatalf("Unexpected field error %d. Expected: %+v - Found: %+v.", i, field, validationErr.Fields[i])
+		}
+	}
+
+	valid := payload{testBase: testBase{ID: "1"}, Named: testAddress{City: "Milan", Zip: "20100"}}
+	if err := NewValidator().Validate(&valid); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+}
+
+// TestValidatorCustomRules verifies custom and unknown rules.
+func TestValidatorCustomRules(t *testing.T) {
+	type payload struct {
+		Value string `json:"value" validate:"uppercase"`
+	}
+
+	v := NewValidator()
+	if err := v.Validate(payload{"a"}); err == nil || !strings.Contains(err.Error(), "unknown validation rule") {
+		t.Fatalf("Unexpected error. Expected unknown rule - Found: %v.", err)
+	}
+
+	v.Register("uppercase", func(value interface{}, _ string) error {
+		if s := value.(string); s != strings.ToUpper(s) {
+			return errors.New("must be uppercase")
+		}
+		return nil
+	})
+	if err := v.Validate(payload{"A"}); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	if err := v.Validate(payload{"a"}); err == nil || err.Error() != "validation failed: /value: must be uppercase" {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+}
+
+// TestValidatorMalformedRules verifies that malformed rule parameters are
+// reported as programming errors instead of field errors.
+func TestValidatorMalformedRules(t *testing.T) {
+	type minPayload struct {
+		Value int `json:"value" validate:"min=abc"`
+	}
+	type regexPayload struct {
+		Value string `json:"value" validate:"regex=[a-"`
+	}
+
+	for _, payload := range []interface{}{minPayload{1}, regexPayload{"a"}} {
+		err := NewValidator().Validate(payload)
+		var validationErr *ValidationError
+		if !errors.Is(err, ErrInvalidRule) || errors.As(err, &validationErr) {
+			t.Fatalf("Unexpected error for %T. Expected: %s - Found: %v.", payload, ErrInvalidRule, err)
+		}
+	}
+
+	h := New()
+	h.SetLogger(log.New(io.Discard, "", 0))
+	h.RegisterRoute(NewRoute(testResourceFunc(func(r *http.Request) (int, Response) {
+		if err := Validate(r, minPayload{1}); err != nil {
+			return MapError(r, err)
+		}
+		return http.StatusOK, nil
+	}), "/"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusInternalServerError, w.Code)
+	}
+}
+
+// TestParseRules verifies the validate tag parsing.
+func TestParseRules(t *testing.T) {
+	rules := parseRules("required,min=1,,regex=^a,b$")
+	expected := [][2]string{{"required", ""}, {"min", "1"}, {"regex", "^a,b$"}}
+	if len(rules) != len(expected) {
+		t.Fatalf("Unexpected rules. Expected: %v - Found: %v.", expected, rules)
+	}
+	for i := range expected {
+		if rules[i] != expected[i] {
+			t.Fatalf("Unexpected rule %d. Expected: %v - Found: %v.", i, expected[i], rules[i])
+		}
+	}
+}
+
+// TestPayloadValidation verifies that decoded payloads are validated before
+// invoking the resource, replying with 422 Unprocessable Entity.
+func TestPayloadValidation(t *testing.T) {
+	res := &testValidatedResource{}
+	h := New()
+	h.RegisterValidator("even", func(value interface{}, _ string) error {
+		if value.(int)%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+	h.RegisterRoute(NewRoute(res, "/"))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"","count":3}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusUnprocessableEntity, w.Code)
+	}
+	expected := `{"status":"NAK","message":"validation failed","errors":[{"pointer":"/name","message":"is required"},{"pointer":"/count","message":"must be even"}]}`
+	if w.Body.String() != expected {
+		t.Fatalf("Unexpected body. Expected: %s - Found: %s.", expected, w.Body.String())
+	}
+	if res.invoked {
+		t.Fatalf("The resource should not have been invoked.")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"fred","count":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, w.Code)
+	}
+	if !res.invoked {
+		t.Fatalf("The resource should have been invoked.")
+	}
+}
+
+// TestValidateFunction verifies the Validate function used by the resources.
+func TestValidateFunction(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" validate:"required"`
+	}
+	if err := Validate(httptest.NewRequest(http.MethodGet, "/", nil), payload{}); err == nil {
+		t.Fatalf("An error was expected. Found nil.")
+	}
+}
+
+// testValidatedResource decodes POST bodies into a validated payload.
+type testValidatedResource struct {
+	invoked bool
+}
+
+func (t *testValidatedResource) NewPayload(method string) interface{} {
+	return &struct {
+		Name  string `json:"name" validate:"required"`
+		Count int    `json:"count" validate:"even"`
+	}{}
+}
+
+func (t *testValidatedResource) Post(r *http.Request) (int, Response) {
+	t.invoked = true
+	return http.StatusOK, nil
+}
@@ -0,0 +1,41 @@
+package gorest
+
+import "encoding/json"
+
+// ValueResponse is a response carrying a Go value instead of a pre-encoded
+// body, gorest encodes the value in the media type negotiated with the client
+// using the Accept header among the ones of the registered encoders.
+type ValueResponse struct {
+	responseHeaders
+	responseCachePolicy
+	code  int
+	value interface{}
+}
+
+// NewValueResponse creates a new ValueResponse carrying provided value.
+func NewValueResponse(value interface{}) *ValueResponse {
+	return &ValueResponse{value: value}
+}
+
+// GetValue will be used by gorest core to retrieve the value to be encoded.
+func (r *ValueResponse) GetValue() interface{} {
+	return r.value
+}
+
+// GetBody returns the JSON encoding of the value, it is used only when the
+// response is not served by gorest.
+func (r *ValueResponse) GetBody() ([]byte, error) {
+	return json.Marshal(r.value)
+}
+
+// SetStatusCode can be used to set the status code of the response returned
+// by the error-returning resource methods, which otherwise is 200 OK.
+func (r *ValueResponse) SetStatusCode(code int) {
+	r.code = code
+}
+
+// GetStatusCode will be used by gorest core to retrieve the status code of
+// the response returned by the error-returning resource methods.
+func (r *ValueResponse) GetStatusCode() int {
+	return r.code
+}
@@ -0,0 +1,493 @@
+package gorest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WebSocket message types, they are the RFC 6455 frame opcodes.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// WebSocket close codes defined by RFC 6455.
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseInternalServerErr       = 1011
+)
+
+// defaultWebSocketReadLimit is the default maximum size of the messages read
+// from a WebSocket connection.
+const defaultWebSocketReadLimit = 1 << 20
+
+// webSocketGUID is the GUID used to compute the Sec-WebSocket-Accept header.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrWebSocketClosed is returned when writing to a closed WebSocket
+// connection.
+var ErrWebSocketClosed = errors.New("websocket: connection closed")
+
+// WebSocketSupported is the interface implemented by the resources accepting
+// WebSocket upgrades, the upgrade requests to their routes are served by
+// ServeWebSocket while the other requests are served by the REST methods.
+// The connection is closed when ServeWebSocket returns.
+//
+// Cross-origin upgrades are rejected unless the resource implements
+// CheckOrigin(r *http.Request) bool, and the subprotocols the resource speaks
+// can be declared implementing Subprotocols() []string.
+type WebSocketSupported interface {
+	ServeWebSocket(conn *WebSocketConn, r *http.Request)
+}
+
+// CloseError is returned when the WebSocket connection has been closed, by
+// the peer or because of a protocol violation.
+type CloseError struct {
+	Code int    // Close code.
+	Text string // Close reason.
+}
+
+// Error returns the description of the closure.
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d: %s", e.Code, e.Text)
+}
+
+// WebSocketConn is a server side RFC 6455 WebSocket connection, messages can
+// be written concurrently with the reads but only one goroutine can read.
+type WebSocketConn struct {
+	conn        net.Conn
+	rw          *bufio.ReadWriter
+	subprotocol string
+	readLimit   int64
+	pongHandler func(data []byte)
+
+	mu        sync.Mutex // Guards the writes.
+	closeSent bool
+}
+
+// Subprotocol returns the subprotocol negotiated during the handshake.
+func (c *WebSocketConn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// SetReadLimit sets the maximum size of the messages read from the peer, the
+// connection is closed with CloseMessageTooBig when exceeded. It defaults to
+// 1 MiB, which is also used when the limit is not positive.
+func (c *WebSocketConn) SetReadLimit(limit int64) {
+	if limit <= 0 {
+		limit = defaultWebSocketReadLimit
+	}
+	c.readLimit = limit
+}
+
+// SetPongHandler sets the function invoked when a pong is received.
+func (c *WebSocketConn) SetPongHandler(handler func(data []byte)) {
+	c.pongHandler = handler
+}
+
+// SetReadDeadline sets the deadline of the reads from the connection.
+func (c *WebSocketConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline of the writes to the connection.
+func (c *WebSocketConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// RemoteAddr returns the address of the peer.
+func (c *WebSocketConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// ReadMessage reads the next text or binary message, pings are answered and
+// pongs are passed to the pong handler. A CloseError is returned when the
+// connection is closed.
+func (c *WebSocketConn) ReadMessage() (int, []byte, error) {
+	var messageType int
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.readFrame(int64(len(message)))
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case PingMessage:
+			if err := c.writeFrame(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if c.pongHandler != nil {
+				c.pongHandler(payload)
+			}
+			continue
+		case CloseMessage:
+			return 0, nil, c.closeReceived(payload)
+		case 0:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			messageType = opcode
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		message = append(message, payload...)
+		if fin {
+			if messageType == TextMessage && !utf8.Valid(message) {
+				return 0, nil, c.fail(CloseInvalidFramePayloadData, "invalid UTF-8 text message")
+			}
+			return messageType, message, nil
+		}
+	}
+}
+
+// WriteMessage writes a text or binary message in a single frame.
+func (c *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", messageType)
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// WritePing writes a ping with the data, which must not exceed 125 bytes.
+func (c *WebSocketConn) WritePing(data []byte) error {
+	if len(data) > 125 {
+		return errors.New("websocket: control frame payload too long")
+	}
+	return c.writeFrame(PingMessage, data)
+}
+
+// Close sends a close frame with the code and the reason, then closes the
+// connection; the peer close frame is not awaited.
+func (c *WebSocketConn) Close(code int, reason string) error {
+	err := c.writeClose(code, reason)
+	if closeErr := c.conn.Close(); err == nil || err == ErrWebSocketClosed {
+		err = closeErr
+	}
+	return err
+}
+
+// readFrame reads a frame from the client, read is the size of the message
+// the frame belongs to read so far.
+func (c *WebSocketConn) readFrame(read int64) (bool, int, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.rw, header[:]); err != nil {
+		return false, 0, nil, c.abnormalClosure(err)
+	}
+	fin := header[0]&0x80 != 0
+	opcode := int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := int64(header[1] & 0x7f)
+
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+	if !masked {
+		return false, 0, nil, c.fail(CloseProtocolError, "client frames must be masked")
+	}
+	isControl := opcode&0x8 != 0
+	if isControl && (!fin || length > 125) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.rw, extended[:]); err != nil {
+			return false, 0, nil, c.abnormalClosure(err)
+		}
+		length = int64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.rw, extended[:]); err != nil {
+			return false, 0, nil, c.abnormalClosure(err)
+		}
+		if extended[0]&0x80 != 0 {
+			return false, 0, nil, c.fail(CloseProtocolError, "invalid payload length")
+		}
+		length = int64(binary.BigEndian.Uint64(extended[:]))
+	}
+	if !isControl && read+length > c.readLimit {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return false, 0, nil, c.abnormalClosure(err)
+	}
+	// The payload is read as it arrives instead of allocating the length
+	// announced by the peer upfront.
+	var buffer bytes.Buffer
+	if _, err := io.CopyN(&buffer, c.rw, length); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return false, 0, nil, c.abnormalClosure(err)
+	}
+	payload := buffer.Bytes()
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame writes a single unmasked frame.
+func (c *WebSocketConn) writeFrame(opcode int, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == CloseMessage {
+		c.closeSent = true
+	}
+
+	header := []byte{0x80 | byte(opcode), 0}
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header[1] = 127
+		header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// writeClose writes a close frame with the code and the reason.
+func (c *WebSocketConn) writeClose(code int, reason string) error {
+	if code == CloseNoStatusReceived {
+		return c.writeFrame(CloseMessage, nil)
+	}
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	return c.writeFrame(CloseMessage, append(payload, reason...))
+}
+
+// closeReceived replies to the close frame sent by the peer and closes the
+// connection.
+func (c *WebSocketConn) closeReceived(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+	switch {
+	case len(payload) == 1:
+		return c.fail(CloseProtocolError, "invalid close frame")
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Text = string(payload[2:])
+		if !validCloseCode(closeErr.Code) {
+			return c.fail(CloseProtocolError, "invalid close code")
+		}
+		if !utf8.ValidString(closeErr.Text) {
+			return c.fail(CloseInvalidFramePayloadData, "invalid UTF-8 close reason")
+		}
+	}
+
+	c.writeClose(closeErr.Code, "")
+	c.conn.Close()
+	return closeErr
+}
+
+// fail closes the connection because of a violation, returning the error.
+func (c *WebSocketConn) fail(code int, text string) error {
+	c.Close(code, text)
+	return &CloseError{Code: code, Text: text}
+}
+
+// abnormalClosure closes the connection lost without a close frame.
+func (c *WebSocketConn) abnormalClosure(err error) error {
+	c.conn.Close()
+	return &CloseError{Code: CloseAbnormalClosure, Text: err.Error()}
+}
+
+// validCloseCode verifies whether the close code can be sent by a peer.
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// isWebSocketUpgrade verifies whether the request asks for a WebSocket
+// upgrade.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+// headerContainsToken verifies whether the comma separated values of the
+// header contain the token, case-insensitively.
+func headerContainsToken(header http.Header, key, token string) bool {
+	for _, value := range header[key] {
+		for _, element := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(element), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// webSocketResponse is returned by the upgrade handler, letting middlewares
+// reject the upgrades before the connection is hijacked.
+type webSocketResponse struct {
+	StandardResponse
+	resource WebSocketSupported
+	request  *http.Request
+}
+
+// webSocketHandler returns the handler upgrading the connection.
+func webSocketHandler(resource WebSocketSupported) Handler {
+	return func(r *http.Request) (int, Response) {
+		return http.StatusSwitchingProtocols, &webSocketResponse{resource: resource, request: r}
+	}
+}
+
+// upgradeWebSocket performs the WebSocket handshake and serves the connection
+// using the resource.
+func (h *RestHandler) upgradeWebSocket(w *responseWriter, response *webSocketResponse) {
+	r := response.request
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		h.writeError(w, r, http.StatusUpgradeRequired, "unsupported WebSocket version")
+		return
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		h.writeError(w, r, http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+		return
+	}
+	if !checkOrigin(response.resource, r) {
+		h.writeError(w, r, http.StatusForbidden, "cross-origin WebSocket upgrade not allowed")
+		return
+	}
+
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		h.logf("gorest: WebSocket upgrade not supported by the response writer for %s %s", r.Method, r.URL.Path)
+		h.writeError(w, r, http.StatusInternalServerError, "WebSocket upgrade not supported")
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		h.logf("gorest: failed hijacking the connection for %s %s: %s", r.Method, r.URL.Path, err.Error())
+		h.writeError(w, r, http.StatusInternalServerError, "WebSocket upgrade failed")
+		return
+	}
+	// The connection is now owned by gorest, nothing can be written through
+	// the response writer anymore.
+	w.wroteHeader = true
+	defer conn.Close()
+
+	ws := &WebSocketConn{
+		conn:        conn,
+		rw:          rw,
+		subprotocol: selectSubprotocol(response.resource, r),
+		readLimit:   defaultWebSocketReadLimit,
+	}
+
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + webSocketAccept(key) + "\r\n"
+	if ws.subprotocol != "" {
+		handshake += "Sec-WebSocket-Protocol: " + ws.subprotocol + "\r\n"
+	}
+	if _, err := rw.WriteString(handshake + "\r\n"); err != nil {
+		return
+	}
+	if err := rw.Flush(); err != nil {
+		return
+	}
+
+	response.resource.ServeWebSocket(ws, r)
+	ws.writeClose(CloseNormalClosure, "")
+}
+
+// webSocketAccept computes the Sec-WebSocket-Accept header from the key.
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// checkOrigin verifies whether the upgrade is allowed for the origin of the
+// request, by default only same-origin upgrades are allowed.
+func checkOrigin(resource WebSocketSupported, r *http.Request) bool {
+	if checker, ok := resource.(interface{ CheckOrigin(r *http.Request) bool }); ok {
+		return checker.CheckOrigin(r)
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// selectSubprotocol returns the first subprotocol requested by the client
+// that the resource speaks.
+func selectSubprotocol(resource WebSocketSupported, r *http.Request) string {
+	speaker, ok := resource.(interface{ Subprotocols() []string })
+	if !ok {
+		return ""
+	}
+	for _, value := range r.Header["Sec-Websocket-Protocol"] {
+		for _, requested := range strings.Split(value, ",") {
+			requested = strings.TrimSpace(requested)
+			for _, supported := range speaker.Subprotocols() {
+				if requested == supported {
+					return requested
+				}
+			}
+		}
+	}
+	return ""
+}
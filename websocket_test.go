@@ -0,0 +1,278 @@
+package gorest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testWebSocketResource echoes the messages and serves GET requests.
+type testWebSocketResource struct{}
+
+func (t testWebSocketResource) Get(r *http.Request) (int, Response) {
+	response := NewStandardResponse()
+	response.SetBody([]byte("rest"))
+	return http.StatusOK, response
+}
+
+func (t testWebSocketResource) ServeWebSocket(conn *WebSocketConn, r *http.Request) {
+	conn.SetReadLimit(16)
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			return
+		}
+	}
+}
+
+func (t testWebSocketResource) Subprotocols() []string {
+	return []string{"echo"}
+}
+
+// testWebSocketClient is a minimal WebSocket client used by the tests.
+type testWebSocketClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// dialWebSocket performs the handshake with the server.
+func dialWebSocket(t *testing.T, server *httptest.Server, headers map[string]string) (*testWebSocketClient, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+
+	request := "GET / HTTP/1.1\r\nHost: " + strings.TrimPrefix(server.URL, "http://") + "\r\n"
+	for key, value := range headers {
+		request += key + ": " + value + "\r\n"
+	}
+	if _, err := io.WriteString(conn, request+"\r\n"); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+
+	reader := bufio.NewReader(conn)
+	res, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	return &testWebSocketClient{t: t, conn: conn, reader: reader}, res
+}
+
+// upgradeHeaders returns the headers of a valid upgrade request.
+func upgradeHeaders() map[string]string {
+	return map[string]string{
+		"Connection":             "keep-alive, Upgrade",
+		"Upgrade":                "websocket",
+		"Sec-WebSocket-Version":  "13",
+		"Sec-WebSocket-Key":      "dGhlIHNhbXBsZSBub25jZQ==",
+		"Sec-WebSocket-Protocol": "chat, echo",
+	}
+}
+
+// writeFrame writes a frame, masked unless specified otherwise.
+func (c *testWebSocketClient) writeFrame(fin bool, opcode byte, payload []byte, masked bool) {
+	header := []byte{opcode, byte(len(payload))}
+	if fin {
+		header[0] |= 0x80
+	}
+	mask := []byte{1, 2, 3, 4}
+	data := append([]byte{}, payload...)
+	if masked {
+		header[1] |= 0x80
+		header = append(header, mask...)
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+	if _, err := c.conn.Write(append(header, data...)); err != nil {
+		c.t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+}
+
+// readFrame reads an unmasked server frame.
+func (c *testWebSocketClient) readFrame() (byte, []byte) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		c.t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		var extended [2]byte
+		io.ReadFull(c.reader, extended[:])
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		c.t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	return header[0] & 0x0f, payload
+}
+
+// expectClose reads a close frame verifying its code.
+func (c *testWebSocketClient) expectClose(code int) {
+	opcode, payload := c.readFrame()
+	if opcode != CloseMessage || len(payload) < 2 {
+		c.t.Fatalf("Unexpected frame. Expected: close - Found: %d %q.", opcode, payload)
+	}
+	if found := int(binary.BigEndian.Uint16(payload)); found != code {
+		c.t.Fatalf("Unexpected close code. Expected: %d - Found: %d.", code, found)
+	}
+}
+
+// newWebSocketServer serves the WebSocket test resource.
+func newWebSocketServer() *httptest.Server {
+	h := New()
+	h.RegisterRoute(NewRoute(testWebSocketResource{}, "/"))
+	return httptest.NewServer(h)
+}
+
+// TestWebSocketHandshakeAndEcho verifies the handshake and the exchange of
+// messages, including fragmented ones and pings.
+func TestWebSocketHandshakeAndEcho(t *testing.T) {
+	server := newWebSocketServer()
+	defer server.Close()
+
+	client, res := dialWebSocket(t, server, upgradeHeaders())
+	defer client.conn.Close()
+
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusSwitchingProtocols, res.StatusCode)
+	}
+	if accept := res.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Unexpected Sec-WebSocket-Accept. Expected: s3pPLMBiTxaQ9kYGzzhZRbK+xOo= - Found: %s.", accept)
+	}
+	if protocol := res.Header.Get("Sec-WebSocket-Protocol"); protocol != "echo" {
+		t.Fatalf("Unexpected Sec-WebSocket-Protocol. Expected: echo - Found: %s.", protocol)
+	}
+
+	client.writeFrame(true, TextMessage, []byte("hello"), true)
+	if opcode, payload := client.readFrame(); opcode != TextMessage || string(payload) != "hello" {
+		t.Fatalf("Unexpected message. Expected: hello - Found: %d %q.", opcode, payload)
+	}
+
+	client.writeFrame(false, BinaryMessage, []byte("he"), true)
+	client.writeFrame(true, PingMessage, []byte("ping"), true)
+	client.writeFrame(true, 0, []byte("llo"), true)
+	if opcode, payload := client.readFrame(); opcode != PongMessage || string(payload) != "ping" {
+		t.Fatalf("Unexpected pong. Expected: ping - Found: %d %q.", opcode, payload)
+	}
+	if opcode, payload := client.readFrame(); opcode != BinaryMessage || string(payload) != "hello" {
+		t.Fatalf("Unexpected message. Expected: hello - Found: %d %q.", opcode, payload)
+	}
+
+	client.writeFrame(true, CloseMessage, []byte{0x03, 0xe8}, true)
+	client.expectClose(CloseNormalClosure)
+}
+
+// TestWebSocketViolations verifies that the connection is closed with the
+// proper code on protocol violations and too big messages.
+func TestWebSocketViolations(t *testing.T) {
+	server := newWebSocketServer()
+	defer server.Close()
+
+	client, _ := dialWebSocket(t, server, upgradeHeaders())
+	client.writeFrame(true, TextMessage, []byte("unmasked"), false)
+	client.expectClose(CloseProtocolError)
+	client.conn.Close()
+
+	client, _ = dialWebSocket(t, server, upgradeHeaders())
+	client.writeFrame(true, TextMessage, []byte(strings.Repeat("a", 17)), true)
+	client.expectClose(CloseMessageTooBig)
+	client.conn.Close()
+
+	client, _ = dialWebSocket(t, server, upgradeHeaders())
+	client.writeFrame(true, TextMessage, []byte{0xff, 0xfe}, true)
+	client.expectClose(CloseInvalidFramePayloadData)
+	client.conn.Close()
+}
+
+// TestWebSocketReadLimit verifies that the read limit cannot be disabled, so
+// that frames announcing huge payloads are rejected before being read.
+func TestWebSocketReadLimit(t *testing.T) {
+	server, peer := net.Pipe()
+	defer peer.Close()
+	conn := &WebSocketConn{conn: server, rw: bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))}
+	conn.SetReadLimit(0)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := conn.ReadMessage()
+		errs <- err
+	}()
+
+	// Binary frame announcing a 2^62 bytes payload.
+	if _, err := peer.Write([]byte{0x82, 0xff, 0x40, 0, 0, 0, 0, 0, 0, 0}); err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	client := &testWebSocketClient{t: t, conn: peer, reader: bufio.NewReader(peer)}
+	client.expectClose(CloseMessageTooBig)
+
+	var closeErr *CloseError
+	if err := <-errs; !errors.As(err, &closeErr) || closeErr.Code != CloseMessageTooBig {
+		t.Fatalf("Unexpected error. Expected: %d - Found: %v.", CloseMessageTooBig, err)
+	}
+}
+
+// TestWebSocketRejectedUpgrades verifies that invalid upgrades are rejected
+// and that the REST methods are still served on the same pattern.
+func TestWebSocketRejectedUpgrades(t *testing.T) {
+	server := newWebSocketServer()
+	defer server.Close()
+
+	tests := []struct {
+		key, value string
+		code       int
+	}{
+		{"Sec-WebSocket-Version", "8", http.StatusUpgradeRequired},
+		{"Sec-WebSocket-Key", "invalid", http.StatusBadRequest},
+		{"Origin", "http://evil.example.com", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		headers := upgradeHeaders()
+		headers[test.key] = test.value
+		client, res := dialWebSocket(t, server, headers)
+		client.conn.Close()
+		if res.StatusCode != test.code {
+			t.Fatalf("Unexpected status code for %s. Expected: %d - Found: %d.", test.key, test.code, res.StatusCode)
+		}
+	}
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err.Error())
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "rest" {
+		t.Fatalf("Unexpected body. Expected: rest - Found: %s.", body)
+	}
+}
+
+// TestWebSocketMiddleware verifies that middlewares can reject upgrades.
+func TestWebSocketMiddleware(t *testing.T) {
+	h := New()
+	h.Use(func(next Handler) Handler {
+		return func(r *http.Request) (int, Response) {
+			return http.StatusUnauthorized, nil
+		}
+	})
+	h.RegisterRoute(NewRoute(testWebSocketResource{}, "/"))
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	client, res := dialWebSocket(t, server, upgradeHeaders())
+	client.conn.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusUnauthorized, res.StatusCode)
+	}
+}
@@ -0,0 +1,43 @@
+package gorest
+
+import "net/http"
+
+// responseWriter wraps the http.ResponseWriter provided to handleRoute
+// tracking whether the status line has already been written.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader writes the status line and the headers.
+func (w *responseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the data, writing the status line first if needed.
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends any buffered data to the client, if supported by the wrapped
+// writer.
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, it is used by http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,38 @@
+package gorest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestResponseWriter verifies that the status line is tracked and written
+// only once.
+func TestResponseWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	w := &responseWriter{ResponseWriter: recorder}
+	if w.wroteHeader {
+		t.Fatalf("The header should not be written yet.")
+	}
+
+	w.Write([]byte("data"))
+	if !w.wroteHeader {
+		t.Fatalf("The header should be written.")
+	}
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, recorder.Code)
+	}
+
+	w.WriteHeader(http.StatusTeapot)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Expected: %d - Found: %d.", http.StatusOK, recorder.Code)
+	}
+
+	w.Flush()
+	if !recorder.Flushed {
+		t.Fatalf("The writer should have been flushed.")
+	}
+	if w.Unwrap() != recorder {
+		t.Fatalf("Unexpected unwrapped writer.")
+	}
+}